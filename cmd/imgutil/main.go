@@ -1,17 +1,47 @@
 package main
 
 import (
-	"fmt"
+	"errors"
 	"os"
 
 	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
 	"github.com/thisisnotashwin/imgutil/internal/image"
 )
 
+// Exit codes, as specified in the design doc.
+const (
+	exitUsage        = 1 // usage errors and anything unclassified
+	exitNotFound     = 2
+	exitUnreachable  = 3
+	exitUnauthorized = 4
+	exitCheckFailed  = 5 // the image failed a CI check, such as --min-efficiency or --fail-on
+)
+
 func main() {
 	loader := image.NewLoader()
-	if err := commands.NewRootCmd(loader).Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(1)
+	root := commands.NewRootCmd(loader)
+	if err := root.Execute(); err != nil {
+		data := errorData(err)
+		output, _ := root.PersistentFlags().GetString("output")
+		_ = format.PrintError(os.Stderr, data, format.Format(output))
+		os.Exit(data.ExitCode)
+	}
+}
+
+// errorData maps err to the kind and exit code reported to the user.
+func errorData(err error) format.ErrorData {
+	if errors.Is(err, commands.ErrCheckFailed) {
+		return format.ErrorData{Error: err.Error(), Kind: "check_failed", ExitCode: exitCheckFailed}
+	}
+	data := format.ErrorData{Error: err.Error(), Kind: image.KindName(err), ExitCode: exitUsage}
+	switch data.Kind {
+	case "not_found":
+		data.ExitCode = exitNotFound
+	case "unreachable":
+		data.ExitCode = exitUnreachable
+	case "unauthorized":
+		data.ExitCode = exitUnauthorized
 	}
+	return data
 }
@@ -0,0 +1,103 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func newAnalyzeCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var (
+		top           int
+		minEfficiency float64
+	)
+
+	cmd := &cobra.Command{
+		Use:   "analyze <image>",
+		Short: "Report layer sizes, wasted space and an efficiency score",
+		Long: `Streams every layer once and reports compressed and uncompressed sizes,
+file counts, and the bytes wasted by files that later layers overwrite or delete.
+
+With --min-efficiency the command fails with exit code 5 when the efficiency
+score falls below the given percentage, for use as a CI gate. The report is
+printed either way.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			img, err := loader.Load(args[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+
+			digest, err := img.Digest()
+			if err != nil {
+				return fmt.Errorf("reading digest: %w", err)
+			}
+
+			layers, err := img.Layers()
+			if err != nil {
+				return fmt.Errorf("reading layers: %w", err)
+			}
+
+			analysis, err := layer.Analyze(layers)
+			if err != nil {
+				return fmt.Errorf("analyzing layers: %w", err)
+			}
+
+			data := format.AnalyzeData{
+				Reference:   args[0],
+				Digest:      digest.String(),
+				Layers:      make([]format.LayerSizeData, 0, len(layers)),
+				WastedBytes: analysis.WastedBytes,
+				Efficiency:  analysis.Efficiency() * 100,
+				Wasted:      []format.WastedPathData{},
+			}
+			for i, l := range layers {
+				d, err := l.Digest()
+				if err != nil {
+					return fmt.Errorf("reading layer %d digest: %w", i, err)
+				}
+				size, err := l.Size()
+				if err != nil {
+					return fmt.Errorf("reading layer %d size: %w", i, err)
+				}
+				stats := analysis.Layers[i]
+				data.Layers = append(data.Layers, format.LayerSizeData{
+					Index:            i,
+					Digest:           d.String(),
+					CompressedSize:   size,
+					UncompressedSize: stats.UncompressedSize,
+					Files:            stats.Files,
+				})
+				data.CompressedBytes += size
+				data.UncompressedBytes += stats.UncompressedSize
+			}
+			for i, w := range analysis.Wasted {
+				if i == top {
+					break
+				}
+				data.Wasted = append(data.Wasted, format.WastedPathData{Path: w.Path, Bytes: w.Bytes, Count: w.Count})
+			}
+
+			if err := format.PrintAnalyze(cmd.OutOrStdout(), data, outFmt); err != nil {
+				return err
+			}
+			if data.Efficiency < minEfficiency {
+				return checkFailed("image efficiency %.2f%% is below --min-efficiency %.2f%%", data.Efficiency, minEfficiency)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&top, "top", 10, "Number of largest wasted paths to list")
+	cmd.Flags().Float64Var(&minEfficiency, "min-efficiency", 0, "Fail if the efficiency score is below this percentage (0-100)")
+
+	return cmd
+}
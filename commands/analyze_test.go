@@ -0,0 +1,84 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+func TestAnalyzeCmd_JSONOutput(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"app/big.bin": strings.Repeat("x", 300), "app/main": "main"},
+		map[string]string{"app/big.bin": "small"},
+	)
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"analyze", "--output", "json", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.AnalyzeData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got.WastedBytes != 300 {
+		t.Errorf("got %d wasted bytes, want 300", got.WastedBytes)
+	}
+	if len(got.Wasted) != 1 || got.Wasted[0].Path != "/app/big.bin" {
+		t.Errorf("got wasted paths %+v, want /app/big.bin", got.Wasted)
+	}
+	if len(got.Layers) != 2 || got.Layers[0].Files != 2 || got.Layers[0].UncompressedSize == 0 {
+		t.Errorf("got layer stats %+v", got.Layers)
+	}
+}
+
+func TestAnalyzeCmd_MinEfficiency(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"big.bin": strings.Repeat("x", 300)},
+		map[string]string{"big.bin": "small"},
+	)
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"analyze", "--min-efficiency", "90", "app:latest"})
+
+	err := root.Execute()
+	if err == nil {
+		t.Fatal("expected error when efficiency is below --min-efficiency")
+	}
+	if !errors.Is(err, commands.ErrCheckFailed) {
+		t.Errorf("got %v, want an error matching ErrCheckFailed", err)
+	}
+	if !strings.Contains(buf.String(), "Efficiency:") {
+		t.Errorf("report should still be printed before failing\ngot: %s", buf.String())
+	}
+}
+
+func TestAnalyzeCmd_HumanOutput(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(randomImage(t)))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"analyze", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	for _, want := range []string{"Efficiency:", "100.00%", "UNCOMPRESSED"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot: %s", want, buf.String())
+		}
+	}
+}
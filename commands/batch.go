@@ -0,0 +1,181 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// batchFlags are the flags of commands that accept many images at once.
+type batchFlags struct {
+	fromFile    string
+	concurrency int
+}
+
+func (b *batchFlags) register(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&b.fromFile, "from-file", "", `Read image references from a file, one per line ("-" for stdin)`)
+	cmd.Flags().IntVar(&b.concurrency, "concurrency", 4, "Number of images to load in parallel")
+}
+
+// refs returns the references given as arguments followed by those read from
+// --from-file. Blank lines and lines starting with # are skipped.
+func (b *batchFlags) refs(cmd *cobra.Command, args []string) ([]string, error) {
+	refs := append([]string(nil), args...)
+	if b.fromFile != "" {
+		var r io.Reader = cmd.InOrStdin()
+		if b.fromFile != "-" {
+			f, err := os.Open(b.fromFile)
+			if err != nil {
+				return nil, err
+			}
+			defer func() { _ = f.Close() }()
+			r = f
+		}
+		sc := bufio.NewScanner(r)
+		for sc.Scan() {
+			line := strings.TrimSpace(sc.Text())
+			if line != "" && !strings.HasPrefix(line, "#") {
+				refs = append(refs, line)
+			}
+		}
+		if err := sc.Err(); err != nil {
+			return nil, fmt.Errorf("reading %s: %w", b.fromFile, err)
+		}
+	}
+	if len(refs) == 0 {
+		return nil, errors.New("no images given: pass references as arguments or with --from-file")
+	}
+	return refs, nil
+}
+
+// batch reports whether the command was given more than one image, or a list
+// of them, and so should produce batch output.
+func (b *batchFlags) batch(refs []string) bool {
+	return b.fromFile != "" || len(refs) > 1
+}
+
+type batchResult[T any] struct {
+	value T
+	err   error
+}
+
+// runBatch loads every ref with load on up to concurrency workers and prints
+// the results in input order as they become ready. In JSON mode each image is
+// one JSON Lines record from jsonLine; otherwise print renders it, under a
+// "==> ref <==" header in human mode. A failed image is reported (as a
+// format.BatchError record in JSON mode, on stderr otherwise) without stopping
+// the rest; runBatch then returns an error counting the failures. If writing
+// the output fails, runBatch stops loading further images and returns the
+// write error.
+func runBatch[T any](
+	cmd *cobra.Command,
+	refs []string,
+	concurrency int,
+	outFmt format.Format,
+	load func(ref string) (T, error),
+	jsonLine func(ref string, v T) any,
+	print func(w io.Writer, v T) error,
+) error {
+	if concurrency < 1 {
+		return errors.New("--concurrency must be at least 1")
+	}
+	name, _, _ := strings.Cut(string(outFmt), "=")
+	if name == string(format.CSV) {
+		return errors.New("csv output is not supported for more than one image")
+	}
+
+	results := make([]chan batchResult[T], len(refs))
+	for i := range results {
+		results[i] = make(chan batchResult[T], 1)
+	}
+	// Cancelling ctx, when output fails, stops handing out refs; the
+	// deferred calls then wait for the loads already under way. Each ref
+	// holds a slot from when it is handed out until its result is printed,
+	// so loads run at most concurrency refs ahead of the output.
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := make(chan int)
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(refs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				v, err := load(refs[i])
+				results[i] <- batchResult[T]{value: v, err: err}
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for i := range refs {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	defer wg.Wait()
+	defer cancel()
+
+	out, errOut := cmd.OutOrStdout(), cmd.ErrOrStderr()
+	failed := 0
+	for i, ref := range refs {
+		res := <-results[i]
+		<-slots
+		if res.err != nil {
+			failed++
+			if outFmt == format.JSON {
+				if err := format.PrintJSONLine(out, format.BatchError{Reference: ref, Error: res.err.Error(), Kind: image.KindName(res.err)}); err != nil {
+					return err
+				}
+			} else {
+				_, _ = fmt.Fprintf(errOut, "Error: %v\n", res.err)
+			}
+			continue
+		}
+
+		var err error
+		switch name {
+		case string(format.JSON):
+			err = format.PrintJSONLine(out, jsonLine(ref, res.value))
+		case string(format.Human), "":
+			if i > 0 {
+				_, _ = fmt.Fprintln(out)
+			}
+			_, _ = fmt.Fprintf(out, "==> %s <==\n", ref)
+			err = print(out, res.value)
+		case string(format.YAML):
+			_, _ = fmt.Fprintln(out, "---")
+			err = print(out, res.value)
+		default:
+			err = print(out, res.value)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d images failed", failed, len(refs))
+	}
+	return nil
+}
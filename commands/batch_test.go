@@ -0,0 +1,163 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// tagLoader serves images by tag from the daemon. Earlier tags load more
+// slowly, so results finish out of input order; unknown tags fail.
+func tagLoader(imgs map[string]v1.Image, order []string) *image.Loader {
+	delay := map[string]time.Duration{}
+	for i, tag := range order {
+		delay[tag] = time.Duration(len(order)-i) * 10 * time.Millisecond
+	}
+	return image.NewLoaderWithFetchers(
+		func(ref name.Reference) (v1.Image, error) {
+			tag := ref.Identifier()
+			time.Sleep(delay[tag])
+			if img, ok := imgs[tag]; ok {
+				return img, nil
+			}
+			return nil, errors.New("no such image")
+		},
+		func(_ name.Reference) (v1.Image, error) { return nil, errors.New("no remote") },
+	)
+}
+
+func TestInspectCmd_BatchJSONLines(t *testing.T) {
+	imgs := map[string]v1.Image{"a": randomImage(t), "c": randomImage(t)}
+	loader := tagLoader(imgs, []string{"a", "b", "c"})
+	root := commands.NewRootCmd(loader)
+
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetArgs([]string{"inspect", "-o", "json", "--concurrency", "3", "app:a", "app:b", "app:c"})
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "1 of 3 images failed") {
+		t.Fatalf("got error %v, want 1 of 3 failed", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3\n%s", len(lines), out.String())
+	}
+	var first, third format.InspectData
+	var second format.BatchError
+	for i, v := range []any{&first, &second, &third} {
+		if err := json.Unmarshal([]byte(lines[i]), v); err != nil {
+			t.Fatalf("line %d: %v\n%s", i, err, lines[i])
+		}
+	}
+	if first.Reference != "app:a" || third.Reference != "app:c" {
+		t.Errorf("got references %q, %q; want input order", first.Reference, third.Reference)
+	}
+	if second.Reference != "app:b" || second.Error == "" || second.Kind == "" {
+		t.Errorf("got error record %+v", second)
+	}
+}
+
+func TestLayersCmd_BatchFromFile(t *testing.T) {
+	imgs := map[string]v1.Image{"a": randomImage(t), "b": randomImage(t)}
+	loader := tagLoader(imgs, []string{"a", "b"})
+	root := commands.NewRootCmd(loader)
+
+	list := filepath.Join(t.TempDir(), "refs.txt")
+	if err := os.WriteFile(list, []byte("# images\napp:a\n\napp:b\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs([]string{"layers", "--from-file", list})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, out.String())
+	}
+	got := out.String()
+	a, b := strings.Index(got, "==> app:a <=="), strings.Index(got, "==> app:b <==")
+	if a < 0 || b < a {
+		t.Errorf("headers missing or out of order\ngot: %s", got)
+	}
+	if strings.Count(got, "DIGEST") != 2 {
+		t.Errorf("want one layer table per image\ngot: %s", got)
+	}
+}
+
+func TestLayersCmd_BatchStdinJSON(t *testing.T) {
+	loader := tagLoader(map[string]v1.Image{"a": randomImage(t)}, []string{"a"})
+	root := commands.NewRootCmd(loader)
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetIn(strings.NewReader("app:a\n"))
+	root.SetArgs([]string{"layers", "-o", "json", "--from-file", "-"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, out.String())
+	}
+	var got format.ImageLayersData
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON line: %v\n%s", err, out.String())
+	}
+	if got.Reference != "app:a" || len(got.Layers) != 2 {
+		t.Errorf("got %s with %d layers, want app:a with 2", got.Reference, len(got.Layers))
+	}
+}
+
+func TestInspectCmd_BatchRejectsRaw(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(randomImage(t)))
+	root.SetOut(&bytes.Buffer{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"inspect", "--raw", "config", "app:a", "app:b"})
+
+	if err := root.Execute(); err == nil {
+		t.Error("expected error for --raw with several images")
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("broken pipe") }
+
+func TestInspectCmd_BatchStopsOnWriteError(t *testing.T) {
+	img := randomImage(t)
+	var loads atomic.Int32
+	loader := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) {
+			loads.Add(1)
+			return img, nil
+		},
+		func(_ name.Reference) (v1.Image, error) { return nil, errors.New("no remote") },
+	)
+	root := commands.NewRootCmd(loader)
+	root.SetOut(failingWriter{})
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs([]string{"inspect", "-o", "json", "--concurrency", "1", "app:a", "app:b", "app:c", "app:d", "app:e"})
+
+	if err := root.Execute(); err == nil || !strings.Contains(err.Error(), "broken pipe") {
+		t.Fatalf("got error %v, want the write error", err)
+	}
+	// The first image fails to print; at most the one loading meanwhile
+	// follows it.
+	if n := loads.Load(); n > 2 {
+		t.Errorf("loaded %d of 5 images after the output failed, want at most 2", n)
+	}
+}
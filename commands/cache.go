@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+func newCacheCmd(flags *GlobalFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the on-disk cache of registry content",
+		Long: `With --cache (or --cache-dir) imgutil keeps the manifests, config blobs and
+layers it downloads from registries in a content-addressed cache, by default
+under $XDG_CACHE_HOME/imgutil. Tags are still resolved against the registry on
+every run, but content already in the cache is not downloaded again.
+
+The cache is kept under --cache-size by evicting the least recently used
+entries at the start of each run that uses it.`,
+	}
+	cmd.AddCommand(newCacheInfoCmd(flags))
+	cmd.AddCommand(newCachePruneCmd(flags))
+	return cmd
+}
+
+func newCacheInfoCmd(flags *GlobalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "info",
+		Short: "Show the cache's location and size",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			c, err := cacheFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			stats, err := c.Stats()
+			if err != nil {
+				return err
+			}
+			return format.PrintCacheInfo(cmd.OutOrStdout(), format.CacheInfoData{
+				Dir:          c.Dir(),
+				Entries:      stats.Entries,
+				SizeBytes:    stats.Size,
+				MaxSizeBytes: c.MaxSize(),
+			}, outFmt)
+		},
+	}
+}
+
+func newCachePruneCmd(flags *GlobalFlags) *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Evict least recently used entries beyond --cache-size",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			c, err := cacheFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			limit := c.MaxSize()
+			if all {
+				limit = 0
+			}
+			removed, err := c.Prune(limit)
+			if err != nil {
+				return err
+			}
+			stats, err := c.Stats()
+			if err != nil {
+				return err
+			}
+			return format.PrintCachePrune(cmd.OutOrStdout(), format.CachePruneData{
+				Dir:          c.Dir(),
+				Removed:      removed.Entries,
+				FreedBytes:   removed.Size,
+				Entries:      stats.Entries,
+				SizeBytes:    stats.Size,
+				MaxSizeBytes: c.MaxSize(),
+			}, outFmt)
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Remove every entry")
+
+	return cmd
+}
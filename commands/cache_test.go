@@ -0,0 +1,83 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func TestCache_SecondRunSkipsDownloads(t *testing.T) {
+	var blobGets atomic.Int32
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/blobs/") {
+			blobGets.Add(1)
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	ref := strings.TrimPrefix(srv.URL, "http://") + "/app:v1"
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(r, randomImage(t)); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	run := func(args ...string) string {
+		t.Helper()
+		root := commands.NewRootCmd(image.NewLoader())
+		var buf bytes.Buffer
+		root.SetOut(&buf)
+		root.SetErr(&buf)
+		root.SetArgs(append(args, "--cache-dir", dir))
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: %v\noutput: %s", args, err, buf.String())
+		}
+		return buf.String()
+	}
+
+	first := run("layers", "--files", "--remote", ref)
+	if blobGets.Load() == 0 {
+		t.Fatal("first run fetched no blobs")
+	}
+	blobGets.Store(0)
+	if second := run("layers", "--files", "--remote", ref); second != first {
+		t.Errorf("cached output differs\nfirst: %s\nsecond: %s", first, second)
+	}
+	if n := blobGets.Load(); n != 0 {
+		t.Errorf("second run fetched %d blobs, want 0", n)
+	}
+
+	var info format.CacheInfoData
+	if err := json.Unmarshal([]byte(run("cache", "info", "-o", "json")), &info); err != nil {
+		t.Fatal(err)
+	}
+	if info.Dir != dir || info.Entries == 0 || info.SizeBytes == 0 {
+		t.Errorf("got cache info %+v", info)
+	}
+
+	var pruned format.CachePruneData
+	if err := json.Unmarshal([]byte(run("cache", "prune", "--all", "-o", "json")), &pruned); err != nil {
+		t.Fatal(err)
+	}
+	if pruned.Removed != info.Entries || pruned.Entries != 0 {
+		t.Errorf("got prune result %+v, want all %d entries removed", pruned, info.Entries)
+	}
+}
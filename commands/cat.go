@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func newCatCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "cat <image> <path>",
+		Short: "Print a file from the image's merged filesystem",
+		Long: `Streams a single file from the image to stdout. Symlinks are followed
+within the image. Every layer's tar headers are scanned to find which layer
+last wrote the file, then its contents are read from that layer alone.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			merged, err := flattenImage(loader, args[0], flags)
+			if err != nil {
+				return err
+			}
+			_, err = merged.Copy(cmd.OutOrStdout(), args[1])
+			return err
+		},
+	}
+}
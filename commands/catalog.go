@@ -0,0 +1,61 @@
+package commands
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func newCatalogCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var (
+		pageSize int
+		last     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "catalog <registry>",
+		Short: "List the repositories on a registry",
+		Long: `Lists the repositories on a registry such as registry.example.com. By default
+every page is fetched. With --page-size only one page is fetched, starting after
+--last; the listing then reports the --last value for the next page.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			if flags.Local {
+				return errors.New("repositories can only be listed from a remote registry")
+			}
+			if pageSize < 0 {
+				return errors.New("--page-size must not be negative")
+			}
+			if last != "" && pageSize == 0 {
+				return errors.New("--last requires --page-size")
+			}
+
+			repos, err := loader.Catalog(args[0], last, pageSize)
+			if err != nil {
+				return err
+			}
+			sort.Strings(repos)
+
+			data := format.CatalogData{Registry: args[0], Repositories: repos}
+			if data.Repositories == nil {
+				data.Repositories = []string{}
+			}
+			if pageSize > 0 && len(repos) == pageSize {
+				data.Next = repos[len(repos)-1]
+			}
+			return format.PrintCatalog(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+
+	cmd.Flags().IntVar(&pageSize, "page-size", 0, "Fetch a single page of at most this many repositories (0 fetches all)")
+	cmd.Flags().StringVar(&last, "last", "", "With --page-size, list repositories after this one")
+
+	return cmd
+}
@@ -0,0 +1,225 @@
+package commands
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func newDiffCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var files bool
+
+	cmd := &cobra.Command{
+		Use:   "diff <imageA> <imageB>",
+		Short: "Compare the config, layers and filesystem of two images",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			imgA, err := loader.Load(args[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+			imgB, err := loader.Load(args[1], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+
+			digestA, err := imgA.Digest()
+			if err != nil {
+				return fmt.Errorf("reading digest of %s: %w", args[0], err)
+			}
+			digestB, err := imgB.Digest()
+			if err != nil {
+				return fmt.Errorf("reading digest of %s: %w", args[1], err)
+			}
+
+			cfgA, err := imgA.ConfigFile()
+			if err != nil {
+				return fmt.Errorf("reading config of %s: %w", args[0], err)
+			}
+			cfgB, err := imgB.ConfigFile()
+			if err != nil {
+				return fmt.Errorf("reading config of %s: %w", args[1], err)
+			}
+
+			layersA, err := imgA.Layers()
+			if err != nil {
+				return fmt.Errorf("reading layers of %s: %w", args[0], err)
+			}
+			layersB, err := imgB.Layers()
+			if err != nil {
+				return fmt.Errorf("reading layers of %s: %w", args[1], err)
+			}
+
+			layerDiff, err := diffLayers(layersA, layersB)
+			if err != nil {
+				return err
+			}
+
+			data := format.DiffData{
+				ImageA:  args[0],
+				DigestA: digestA.String(),
+				ImageB:  args[1],
+				DigestB: digestB.String(),
+				Config:  diffConfig(cfgA.Config, cfgB.Config),
+				Layers:  layerDiff,
+			}
+
+			if files {
+				fsA, err := layer.Flatten(layersA)
+				if err != nil {
+					return fmt.Errorf("reading filesystem of %s: %w", args[0], err)
+				}
+				fsB, err := layer.Flatten(layersB)
+				if err != nil {
+					return fmt.Errorf("reading filesystem of %s: %w", args[1], err)
+				}
+				data.Files = []format.FileChange{}
+				for _, c := range layer.Diff(fsA, fsB) {
+					data.Files = append(data.Files, format.FileChange{Path: c.Path, Kind: string(c.Kind)})
+				}
+			}
+
+			return format.PrintDiff(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+
+	cmd.Flags().BoolVar(&files, "files", false, "Also compare the merged filesystems (downloads every layer)")
+
+	return cmd
+}
+
+// diffConfig reports the runtime config fields that differ between a and b.
+// Env, labels and ports are compared per key so a single changed variable
+// does not show up as the whole list changing.
+func diffConfig(a, b v1.Config) []format.ConfigChange {
+	var changes []format.ConfigChange
+	add := func(field string, va, vb *string) {
+		if (va == nil) != (vb == nil) || va != nil && *va != *vb {
+			changes = append(changes, format.ConfigChange{Field: field, A: va, B: vb})
+		}
+	}
+	// The scalar fields are unset when empty.
+	addScalar := func(field, va, vb string) {
+		add(field, setting(va), setting(vb))
+	}
+	// Keyed fields are compared by presence, so FOO= differs from no FOO.
+	addKeyed := func(prefix string, ma, mb map[string]string) {
+		for _, k := range unionKeys(ma, mb) {
+			add(prefix+k, lookup(ma, k), lookup(mb, k))
+		}
+	}
+
+	// Argument lists are unset when nil, so an explicit [] differs from none.
+	add("entrypoint", argList(a.Entrypoint), argList(b.Entrypoint))
+	add("cmd", argList(a.Cmd), argList(b.Cmd))
+	addScalar("user", a.User, b.User)
+	addScalar("workdir", a.WorkingDir, b.WorkingDir)
+
+	addKeyed("env.", envMap(a.Env), envMap(b.Env))
+	addKeyed("label.", a.Labels, b.Labels)
+
+	portsA, portsB := map[string]string{}, map[string]string{}
+	for p := range a.ExposedPorts {
+		portsA[p] = "exposed"
+	}
+	for p := range b.ExposedPorts {
+		portsB[p] = "exposed"
+	}
+	addKeyed("port.", portsA, portsB)
+
+	return changes
+}
+
+// diffLayers splits two layer stacks at the first digest that differs.
+func diffLayers(a, b []v1.Layer) (format.LayerDiff, error) {
+	digestsA, err := layerDigests(a)
+	if err != nil {
+		return format.LayerDiff{}, err
+	}
+	digestsB, err := layerDigests(b)
+	if err != nil {
+		return format.LayerDiff{}, err
+	}
+
+	shared := 0
+	for shared < len(digestsA) && shared < len(digestsB) && digestsA[shared] == digestsB[shared] {
+		shared++
+	}
+	return format.LayerDiff{
+		Shared: shared,
+		OnlyA:  digestsA[shared:],
+		OnlyB:  digestsB[shared:],
+	}, nil
+}
+
+func layerDigests(layers []v1.Layer) ([]string, error) {
+	digests := make([]string, 0, len(layers))
+	for i, l := range layers {
+		d, err := l.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("reading layer %d digest: %w", i, err)
+		}
+		digests = append(digests, d.String())
+	}
+	return digests, nil
+}
+
+// argList formats args with each element quoted, or returns nil if args is
+// nil.
+func argList(args []string) *string {
+	if args == nil {
+		return nil
+	}
+	s := fmt.Sprintf("%q", args)
+	return &s
+}
+
+func envMap(env []string) map[string]string {
+	m := make(map[string]string, len(env))
+	for _, e := range env {
+		k, v, _ := strings.Cut(e, "=")
+		m[k] = v
+	}
+	return m
+}
+
+// setting returns a pointer to s, or nil if s is empty.
+func setting(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// lookup returns a pointer to m[k], or nil if m has no k.
+func lookup(m map[string]string, k string) *string {
+	if v, ok := m[k]; ok {
+		return &v
+	}
+	return nil
+}
+
+func unionKeys(a, b map[string]string) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
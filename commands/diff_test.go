@@ -0,0 +1,194 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// refLoader serves a different image per reference from the daemon fetcher.
+func refLoader(images map[string]v1.Image) *image.Loader {
+	return image.NewLoaderWithFetchers(
+		func(ref name.Reference) (v1.Image, error) {
+			if img, ok := images[ref.Name()]; ok {
+				return img, nil
+			}
+			return nil, fmt.Errorf("no image %s", ref.Name())
+		},
+		func(ref name.Reference) (v1.Image, error) { return nil, errors.New("no remote") },
+	)
+}
+
+func TestDiffCmd_JSONOutput(t *testing.T) {
+	base := randomImage(t)
+	changed, err := mutate.Config(base, v1.Config{Env: []string{"VERSION=2"}, User: "app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err = mutate.AppendLayers(changed, mustLayers(t, randomImage(t))[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	loader := refLoader(map[string]v1.Image{
+		"index.docker.io/library/app:1": base,
+		"index.docker.io/library/app:2": changed,
+	})
+	root := commands.NewRootCmd(loader)
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"diff", "--files", "--output", "json", "app:1", "app:2"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.DiffData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got.Layers.Shared != 2 || len(got.Layers.OnlyA) != 0 || len(got.Layers.OnlyB) != 1 {
+		t.Errorf("got layer diff %+v, want 2 shared and 1 only in B", got.Layers)
+	}
+	fields := map[string]bool{}
+	for _, c := range got.Config {
+		fields[c.Field] = true
+	}
+	if !fields["env.VERSION"] || !fields["user"] {
+		t.Errorf("config diff missing env.VERSION or user: %+v", got.Config)
+	}
+	if len(got.Files) != 1 || got.Files[0].Kind != "added" {
+		t.Errorf("got file changes %+v, want one added file", got.Files)
+	}
+}
+
+func TestDiffCmd_HumanOutput(t *testing.T) {
+	img := randomImage(t)
+	loader := refLoader(map[string]v1.Image{
+		"index.docker.io/library/app:1": img,
+		"index.docker.io/library/app:2": img,
+	})
+	root := commands.NewRootCmd(loader)
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"diff", "app:1", "app:2"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Layers: 2 shared, 0 only in A, 0 only in B") {
+		t.Errorf("output missing layer summary\ngot: %s", out)
+	}
+	if !strings.Contains(out, "no differences") {
+		t.Errorf("output missing empty config diff\ngot: %s", out)
+	}
+}
+
+func TestDiffCmd_RequiresTwoArguments(t *testing.T) {
+	loader := daemonLoader(randomImage(t))
+	root := commands.NewRootCmd(loader)
+	root.SetArgs([]string{"diff", "alpine:latest"})
+
+	if err := root.Execute(); err == nil {
+		t.Error("expected error when only one image argument provided")
+	}
+}
+
+func mustLayers(t *testing.T, img v1.Image) []v1.Layer {
+	t.Helper()
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return layers
+}
+
+func TestDiffCmd_EmptyValues(t *testing.T) {
+	base := randomImage(t)
+	set, err := mutate.Config(base, v1.Config{
+		Env:        []string{"FOO="},
+		Labels:     map[string]string{"tier": ""},
+		Entrypoint: []string{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	loader := refLoader(map[string]v1.Image{
+		"index.docker.io/library/app:1": base,
+		"index.docker.io/library/app:2": set,
+	})
+	root := commands.NewRootCmd(loader)
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"diff", "--output", "json", "app:1", "app:2"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(buf.Bytes(), &raw); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if files, ok := raw["files"]; !ok || string(files) != "null" {
+		t.Errorf("got files %s, want null when the filesystems were not compared", files)
+	}
+
+	var got format.DiffData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	changes := map[string]format.ConfigChange{}
+	for _, c := range got.Config {
+		changes[c.Field] = c
+	}
+	for _, field := range []string{"env.FOO", "label.tier"} {
+		c, ok := changes[field]
+		if !ok || c.A != nil || c.B == nil || *c.B != "" {
+			t.Errorf("%s: got %+v, want unset in A and empty in B", field, c)
+		}
+	}
+	if c, ok := changes["entrypoint"]; !ok || c.A != nil || c.B == nil || *c.B != "[]" {
+		t.Errorf("entrypoint: got %+v, want unset in A and [] in B", c)
+	}
+}
+
+func TestDiffCmd_FilesIdentical(t *testing.T) {
+	img := randomImage(t)
+	loader := refLoader(map[string]v1.Image{
+		"index.docker.io/library/app:1": img,
+		"index.docker.io/library/app:2": img,
+	})
+	root := commands.NewRootCmd(loader)
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"diff", "--files", "--output", "json", "app:1", "app:2"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.DiffData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got.Files == nil || len(got.Files) != 0 {
+		t.Errorf("got files %#v, want an empty list", got.Files)
+	}
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func newDigestCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var pin bool
+
+	cmd := &cobra.Command{
+		Use:   "digest <image>",
+		Short: "Resolve a reference to its digest without downloading the image",
+		Long: `Resolves a reference with a single HEAD request (falling back to GET) and
+reports the manifest digest, media type and size. For a multi-platform image
+this is the digest of the index. Daemon images report their image ID and repo
+digests instead.
+
+--pin prints the reference rewritten as repo:tag@sha256:..., for pinning images
+in deployment manifests.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			desc, err := loader.Describe(args[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+
+			data := format.DigestData{
+				Reference:   args[0],
+				Source:      desc.Source,
+				Digest:      desc.Digest,
+				MediaType:   desc.MediaType,
+				Size:        desc.Size,
+				ImageID:     desc.ImageID,
+				RepoDigests: desc.RepoDigests,
+			}
+			if pin {
+				if data.Pinned, err = pinReference(args[0], desc); err != nil {
+					return err
+				}
+			}
+
+			return format.PrintDigest(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+
+	cmd.Flags().BoolVar(&pin, "pin", false, "Print the reference as repo:tag@digest")
+
+	return cmd
+}
+
+// pinReference rewrites rawRef in full as repo:tag@digest, using the default
+// tag when rawRef has none. A reference that already carries a digest must
+// carry the resolved one. Daemon images are pinned to the repo digest
+// recorded for the same repository, which exists only once the image was
+// pushed or pulled.
+func pinReference(rawRef string, desc *image.Descriptor) (string, error) {
+	if desc.Source != "registry" && desc.Source != "daemon" {
+		return "", fmt.Errorf("--pin needs a registry or daemon reference, not %s", desc.Source)
+	}
+	ref, err := name.ParseReference(rawRef)
+	if err != nil {
+		return "", err
+	}
+
+	digest := desc.Digest
+	if digest == "" {
+		for _, rd := range desc.RepoDigests {
+			d, err := name.NewDigest(rd)
+			if err == nil && d.Context().Name() == ref.Context().Name() {
+				digest = d.DigestStr()
+				break
+			}
+		}
+		if digest == "" {
+			return "", fmt.Errorf("%s has no repo digest for %s; push it or use --remote", rawRef, ref.Context())
+		}
+	}
+
+	pinned := ref.Context().Name()
+	switch r := ref.(type) {
+	case name.Tag:
+		pinned += ":" + r.TagStr()
+	case name.Digest:
+		if r.DigestStr() != digest {
+			return "", fmt.Errorf("%s resolves to %s, not the digest it names", rawRef, digest)
+		}
+		// Keep a tag written alongside the digest, as in repo:tag@sha256:...
+		base, _, _ := strings.Cut(rawRef, "@")
+		if strings.Contains(base[strings.LastIndex(base, "/")+1:], ":") {
+			if t, err := name.NewTag(base); err == nil {
+				pinned += ":" + t.TagStr()
+			}
+		}
+	}
+	return pinned + "@" + digest, nil
+}
@@ -0,0 +1,129 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func TestDigestCmd_Registry(t *testing.T) {
+	rawRef := startRegistry(t) + "/app:1.0"
+	img := randomImage(t)
+	ref, err := name.ParseReference(rawRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := commands.NewRootCmd(image.NewLoader())
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"digest", "--remote", "--output", "json", rawRef})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.DigestData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got.Source != "registry" || got.Digest != want.String() || got.Size == 0 || got.MediaType == "" {
+		t.Errorf("got %+v, want registry digest %s", got, want)
+	}
+
+	root = commands.NewRootCmd(image.NewLoader())
+	buf.Reset()
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"digest", "--remote", "--pin", rawRef})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if got := strings.TrimSpace(buf.String()); got != rawRef+"@"+want.String() {
+		t.Errorf("got pinned %q, want %q", got, rawRef+"@"+want.String())
+	}
+}
+
+func TestDigestCmd_Pin(t *testing.T) {
+	repo := startRegistry(t) + "/app"
+	img := randomImage(t)
+	for _, tag := range []string{"latest", "1.0"} {
+		ref, err := name.ParseReference(repo + ":" + tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := d.String()
+
+	cases := []struct{ ref, want string }{
+		{repo, repo + ":latest@" + digest},
+		{repo + ":1.0", repo + ":1.0@" + digest},
+		{repo + ":1.0@" + digest, repo + ":1.0@" + digest},
+		{repo + "@" + digest, repo + "@" + digest},
+	}
+	for _, tc := range cases {
+		root := commands.NewRootCmd(image.NewLoader())
+		var buf bytes.Buffer
+		root.SetOut(&buf)
+		root.SetErr(&buf)
+		root.SetArgs([]string{"digest", "--remote", "--pin", tc.ref})
+
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%s: unexpected error: %v\noutput: %s", tc.ref, err, buf.String())
+		}
+		if got := strings.TrimSpace(buf.String()); got != tc.want {
+			t.Errorf("%s: got pinned %q, want %q", tc.ref, got, tc.want)
+		}
+	}
+}
+
+func TestDigestCmd_Daemon(t *testing.T) {
+	img := randomImage(t)
+	id, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"digest", "--local", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), "Image ID:") || !strings.Contains(buf.String(), id.String()) {
+		t.Errorf("output missing image ID %s\ngot: %s", id, buf.String())
+	}
+
+	root = commands.NewRootCmd(daemonLoader(img))
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"digest", "--local", "--pin", "app:latest"})
+	if err := root.Execute(); err == nil {
+		t.Error("expected error pinning a daemon image without repo digests")
+	}
+}
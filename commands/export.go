@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func newExportCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var to string
+
+	cmd := &cobra.Command{
+		Use:   "export <image> --to <dir|file.tar|->",
+		Short: "Extract the image's merged filesystem to a directory or tarball",
+		Long: `Writes the filesystem the image's layers produce once whiteouts are applied.
+A --to ending in .tar writes a single flattened tarball, "-" writes that tarball
+to stdout, and anything else is a directory that must be empty or not exist.
+
+Directory exports keep permissions, modification times, symlinks and hard
+links, and ownership when run as root. Entries that would land outside the
+target, through ".." or a symlink, are refused.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			merged, err := flattenImage(loader, args[0], flags)
+			if err != nil {
+				return err
+			}
+
+			switch {
+			case to == "-":
+				return merged.WriteTar(cmd.OutOrStdout())
+			case strings.HasSuffix(to, ".tar"):
+				f, err := os.OpenFile(to, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644) //nolint:gosec // G304: user-chosen output path
+				if err != nil {
+					return fmt.Errorf("creating %s: %w", to, err)
+				}
+				if err := merged.WriteTar(f); err != nil {
+					_ = f.Close()
+					return errors.Join(err, os.Remove(to))
+				}
+				return f.Close()
+			default:
+				if err := merged.Extract(to); err != nil {
+					return fmt.Errorf("extracting to %s: %w", to, err)
+				}
+				return nil
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&to, "to", "", `Destination directory, .tar file, or "-" for a tarball on stdout`)
+	_ = cmd.MarkFlagRequired("to")
+
+	return cmd
+}
@@ -0,0 +1,59 @@
+package commands_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/commands"
+)
+
+func TestExportCmd_Dir(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"etc/": "", "etc/hosts": "a", "etc/passwd": "root"},
+		map[string]string{"etc/.wh.passwd": "", "etc/hosts": "b"},
+	)
+	root := commands.NewRootCmd(daemonLoader(img))
+	dir := filepath.Join(t.TempDir(), "rootfs")
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"export", "app:latest", "--to", dir})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "etc/hosts"))
+	if err != nil || string(got) != "b" {
+		t.Errorf("got %q (%v) for /etc/hosts, want b", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "etc/passwd")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("whited-out /etc/passwd was exported: %v", err)
+	}
+}
+
+func TestExportCmd_Stdout(t *testing.T) {
+	img := layeredImage(t, map[string]string{"etc/hosts": "a"})
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(io.Discard)
+	root.SetArgs([]string{"export", "app:latest", "--to", "-"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	hdr, err := tar.NewReader(&buf).Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hdr.Name != "etc/hosts" {
+		t.Errorf("got entry %q, want etc/hosts", hdr.Name)
+	}
+}
@@ -0,0 +1,89 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func newHistoryCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "history <image>",
+		Short: "Show every build step, including those that added no layer",
+		Long: `Lists the image's history entries in build order with their creation time,
+author, comment and the digest of the layer each step produced. Metadata-only
+steps such as ENV, LABEL and CMD produce no layer.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			img, err := loader.Load(args[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+			cfg, err := img.ConfigFile()
+			if err != nil {
+				return fmt.Errorf("reading config: %w", err)
+			}
+			layers, err := img.Layers()
+			if err != nil {
+				return fmt.Errorf("reading layers: %w", err)
+			}
+
+			data := make([]format.HistoryData, 0, len(cfg.History))
+			next := 0
+			for i, h := range cfg.History {
+				d := format.HistoryData{
+					Index:      i,
+					CreatedBy:  stepCommand(&cfg.History[i]),
+					Author:     h.Author,
+					Comment:    h.Comment,
+					EmptyLayer: h.EmptyLayer,
+				}
+				if !h.Created.IsZero() {
+					d.Created = h.Created.UTC().Format(time.RFC3339)
+				}
+				if !h.EmptyLayer && next < len(layers) {
+					digest, err := layers[next].Digest()
+					if err != nil {
+						return fmt.Errorf("reading layer %d digest: %w", next, err)
+					}
+					d.Layer = digest.String()
+					next++
+				}
+				data = append(data, d)
+			}
+
+			return format.PrintHistory(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+}
+
+// layerHistory pairs history entries with layers. Entries marked EmptyLayer
+// (ENV, LABEL, CMD and other metadata-only steps) produced no layer and are
+// skipped, so element i describes layer i. The result is shorter than the
+// layer list when the image records less history than it has layers.
+func layerHistory(cfg *v1.ConfigFile) []*v1.History {
+	history := make([]*v1.History, 0, len(cfg.History))
+	for i := range cfg.History {
+		if !cfg.History[i].EmptyLayer {
+			history = append(history, &cfg.History[i])
+		}
+	}
+	return history
+}
+
+// stepCommand returns the Dockerfile step that h records, without the shell
+// prefix the builder adds.
+func stepCommand(h *v1.History) string {
+	createdBy := strings.TrimPrefix(h.CreatedBy, "|0 /bin/sh -c ")
+	return strings.TrimPrefix(createdBy, "/bin/sh -c ")
+}
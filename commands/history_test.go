@@ -0,0 +1,98 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+// dockerfileImage has two layers and history interleaving metadata-only steps
+// the way a real Dockerfile build records it.
+func dockerfileImage(t *testing.T) v1.Image {
+	t.Helper()
+	img := layeredImage(t,
+		map[string]string{"etc/os-release": "ID=test"},
+		map[string]string{"app/main": "main"},
+	)
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.History = []v1.History{
+		{CreatedBy: "/bin/sh -c #(nop) ADD file:abc in /", Created: v1.Time{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)}},
+		{CreatedBy: "/bin/sh -c #(nop)  ENV APP=1", EmptyLayer: true},
+		{CreatedBy: "/bin/sh -c #(nop) COPY main /app/main", Author: "dev", Comment: "build"},
+		{CreatedBy: "/bin/sh -c #(nop)  CMD [\"/app/main\"]", EmptyLayer: true},
+	}
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
+func TestHistoryCmd_JSONOutput(t *testing.T) {
+	img := dockerfileImage(t)
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := layers[1].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"history", "--output", "json", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got []format.HistoryData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got) != 4 {
+		t.Fatalf("got %d history entries, want 4", len(got))
+	}
+	if got[0].Created != "2026-01-02T03:04:05Z" || got[0].Layer == "" {
+		t.Errorf("got first entry %+v", got[0])
+	}
+	if !got[1].EmptyLayer || got[1].Layer != "" {
+		t.Errorf("ENV step should have no layer, got %+v", got[1])
+	}
+	if got[2].Layer != second.String() || got[2].Author != "dev" || got[2].Comment != "build" {
+		t.Errorf("COPY step should produce layer 2 %s, got %+v", second, got[2])
+	}
+}
+
+func TestLayersCmd_SkipsEmptyLayerHistory(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(dockerfileImage(t)))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"layers", "--output", "json", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got []format.LayerData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got) != 2 || !strings.Contains(got[1].Command, "COPY main") {
+		t.Errorf("layer 2 should map to the COPY step, got %+v", got)
+	}
+}
@@ -0,0 +1,68 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func newIndexCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "index <image>",
+		Short: "List the platform manifests of a multi-platform image index",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			if flags.Local {
+				return errors.New("indexes can only be read from a remote registry")
+			}
+
+			idx, err := loader.LoadIndex(args[0])
+			if err != nil {
+				return err
+			}
+
+			digest, err := idx.Digest()
+			if err != nil {
+				return fmt.Errorf("reading digest: %w", err)
+			}
+
+			mediaType, err := idx.MediaType()
+			if err != nil {
+				return fmt.Errorf("reading media type: %w", err)
+			}
+
+			manifest, err := idx.IndexManifest()
+			if err != nil {
+				return fmt.Errorf("reading index manifest: %w", err)
+			}
+
+			data := format.IndexData{
+				Reference: args[0],
+				Digest:    digest.String(),
+				MediaType: string(mediaType),
+				Manifests: make([]format.ManifestData, 0, len(manifest.Manifests)),
+			}
+			for _, desc := range manifest.Manifests {
+				m := format.ManifestData{
+					Digest:    desc.Digest.String(),
+					MediaType: string(desc.MediaType),
+					Size:      desc.Size,
+				}
+				if desc.Platform != nil {
+					m.Platform = desc.Platform.String()
+				}
+				data.Manifests = append(data.Manifests, m)
+			}
+
+			return format.PrintIndex(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+}
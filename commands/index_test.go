@@ -0,0 +1,131 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// startRegistry runs an in-memory registry for the duration of the test and
+// returns its host:port.
+func startRegistry(t *testing.T) string {
+	t.Helper()
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://")
+}
+
+// pushIndex writes a two-platform index (linux/amd64, linux/arm64) to ref.
+func pushIndex(t *testing.T, ref string) v1.ImageIndex {
+	t.Helper()
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: randomImage(t), Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "linux", Architecture: "amd64"},
+		}},
+		mutate.IndexAddendum{Add: randomImage(t), Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "linux", Architecture: "arm64"},
+		}},
+	)
+	r, err := name.ParseReference(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(r, idx); err != nil {
+		t.Fatal(err)
+	}
+	return idx
+}
+
+func TestIndexCmd_JSONOutput(t *testing.T) {
+	ref := startRegistry(t) + "/app:multi"
+	pushIndex(t, ref)
+	root := commands.NewRootCmd(image.NewLoader())
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"index", "--output", "json", ref})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.IndexData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got.Manifests) != 2 {
+		t.Fatalf("got %d manifests, want 2", len(got.Manifests))
+	}
+	if got.Manifests[1].Platform != "linux/arm64" {
+		t.Errorf("got platform %q, want linux/arm64", got.Manifests[1].Platform)
+	}
+}
+
+func TestIndexCmd_RejectsLocal(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(randomImage(t)))
+	root.SetArgs([]string{"index", "--local", "alpine:latest"})
+
+	if err := root.Execute(); err == nil {
+		t.Error("expected error when --local is set")
+	}
+}
+
+func TestIndexCmd_RejectsSchemeRefs(t *testing.T) {
+	for _, ref := range []string{"oci:./out", "docker-archive:app.tar"} {
+		root := commands.NewRootCmd(image.NewLoader())
+		root.SetArgs([]string{"index", ref})
+
+		err := root.Execute()
+		if !errors.Is(err, image.ErrInvalidReference) {
+			t.Errorf("%s: got %v, want ErrInvalidReference", ref, err)
+		} else if !strings.Contains(err.Error(), "index does not support") {
+			t.Errorf("%s: unclear error %q", ref, err)
+		}
+	}
+}
+
+func TestInspectCmd_ReportsIndex(t *testing.T) {
+	ref := startRegistry(t) + "/app:multi"
+	pushIndex(t, ref)
+	root := commands.NewRootCmd(image.NewLoader())
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"inspect", "--remote", "--platform", "linux/arm64", "--output", "json", ref})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.InspectData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got.Index == "" {
+		t.Error("expected index digest to be reported")
+	}
+}
+
+func TestInspectCmd_InvalidPlatform(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(randomImage(t)))
+	root.SetArgs([]string{"inspect", "--platform", "linux/arm64/v8/extra", "alpine:latest"})
+
+	if err := root.Execute(); err == nil {
+		t.Error("expected error for invalid platform")
+	}
+}
@@ -1,8 +1,11 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"sort"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/thisisnotashwin/imgutil/internal/format"
@@ -10,52 +13,165 @@ import (
 )
 
 func newInspectCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
-	return &cobra.Command{
-		Use:   "inspect <image>",
+	var (
+		raw   string
+		batch batchFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "inspect <image>...",
 		Short: "Display image configuration metadata",
-		Args:  cobra.ExactArgs(1),
+		Long: `Displays the image's configuration. With --raw config or --raw manifest the
+config blob or manifest is written byte for byte instead, ignoring --output.
+
+Several images may be given as arguments or listed with --from-file; they are
+loaded in parallel and reported in input order, one JSON Lines record each
+with --output json. An image that fails to load is reported without stopping
+the rest.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			img, err := loader.Load(args[0], sourceFromFlags(flags))
+			outFmt, err := formatFromFlags(flags)
 			if err != nil {
 				return err
 			}
-
-			cfg, err := img.ConfigFile()
-			if err != nil {
-				return fmt.Errorf("reading config: %w", err)
+			if raw != "" && raw != "config" && raw != "manifest" {
+				return fmt.Errorf("invalid --raw %q: want config or manifest", raw)
 			}
-
-			digest, err := img.Digest()
+			refs, err := batch.refs(cmd, args)
 			if err != nil {
-				return fmt.Errorf("reading digest: %w", err)
+				return err
 			}
 
-			size, err := img.Size()
-			if err != nil {
-				return fmt.Errorf("reading size: %w", err)
+			if batch.batch(refs) {
+				if raw != "" {
+					return errors.New("--raw takes a single image")
+				}
+				return runBatch(cmd, refs, batch.concurrency, outFmt,
+					func(ref string) (format.InspectData, error) {
+						return inspectImage(loader, ref, sourceFromFlags(flags))
+					},
+					func(_ string, data format.InspectData) any { return data },
+					func(w io.Writer, data format.InspectData) error {
+						return format.PrintInspect(w, data, outFmt)
+					},
+				)
 			}
 
-			ports := make([]string, 0, len(cfg.Config.ExposedPorts))
-			for p := range cfg.Config.ExposedPorts {
-				ports = append(ports, string(p))
-			}
-			sort.Strings(ports)
-
-			data := format.InspectData{
-				Reference:  args[0],
-				Digest:     digest.String(),
-				OS:         cfg.OS,
-				Arch:       cfg.Architecture,
-				Created:    cfg.Created.UTC().Format("2006-01-02 15:04:05 UTC"),
-				SizeBytes:  size,
-				Entrypoint: cfg.Config.Entrypoint,
-				Cmd:        cfg.Config.Cmd,
-				Env:        cfg.Config.Env,
-				Ports:      ports,
-				Labels:     cfg.Config.Labels,
+			if raw != "" {
+				img, err := loader.Load(refs[0], sourceFromFlags(flags))
+				if err != nil {
+					return err
+				}
+				var b []byte
+				if raw == "config" {
+					if b, err = img.RawConfigFile(); err != nil {
+						return fmt.Errorf("reading config: %w", err)
+					}
+				} else if b, err = img.RawManifest(); err != nil {
+					return fmt.Errorf("reading manifest: %w", err)
+				}
+				_, err = cmd.OutOrStdout().Write(b)
+				return err
 			}
 
-			return format.PrintInspect(cmd.OutOrStdout(), data, formatFromFlags(flags))
+			data, err := inspectImage(loader, refs[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+			return format.PrintInspect(cmd.OutOrStdout(), data, outFmt)
 		},
 	}
+
+	cmd.Flags().StringVar(&raw, "raw", "", "Write the raw config or manifest (config|manifest) instead")
+	batch.register(cmd)
+
+	return cmd
+}
+
+// inspectImage loads ref and collects its configuration for display.
+func inspectImage(loader *image.Loader, ref string, src image.Source) (format.InspectData, error) {
+	res, err := loader.Resolve(ref, src)
+	if err != nil {
+		return format.InspectData{}, err
+	}
+	img := res.Image
+
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return format.InspectData{}, fmt.Errorf("reading config: %w", err)
+	}
+
+	digest, err := img.Digest()
+	if err != nil {
+		return format.InspectData{}, fmt.Errorf("reading digest: %w", err)
+	}
+
+	size, err := img.Size()
+	if err != nil {
+		return format.InspectData{}, fmt.Errorf("reading size: %w", err)
+	}
+
+	ports := make([]string, 0, len(cfg.Config.ExposedPorts))
+	for p := range cfg.Config.ExposedPorts {
+		ports = append(ports, string(p))
+	}
+	sort.Strings(ports)
+
+	volumes := make([]string, 0, len(cfg.Config.Volumes))
+	for v := range cfg.Config.Volumes {
+		volumes = append(volumes, v)
+	}
+	sort.Strings(volumes)
+
+	data := format.InspectData{
+		Reference:     ref,
+		Digest:        digest.String(),
+		OS:            cfg.OS,
+		Arch:          cfg.Architecture,
+		Variant:       cfg.Variant,
+		OSVersion:     cfg.OSVersion,
+		OSFeatures:    cfg.OSFeatures,
+		Created:       cfg.Created.UTC().Format("2006-01-02 15:04:05 UTC"),
+		Author:        cfg.Author,
+		DockerVersion: cfg.DockerVersion,
+		SizeBytes:     size,
+		User:          cfg.Config.User,
+		WorkingDir:    cfg.Config.WorkingDir,
+		Entrypoint:    cfg.Config.Entrypoint,
+		Cmd:           cfg.Config.Cmd,
+		Shell:         cfg.Config.Shell,
+		ArgsEscaped:   cfg.Config.ArgsEscaped,
+		Env:           cfg.Config.Env,
+		Ports:         ports,
+		Volumes:       volumes,
+		Labels:        cfg.Config.Labels,
+		StopSignal:    cfg.Config.StopSignal,
+		OnBuild:       cfg.Config.OnBuild,
+	}
+	if hc := cfg.Config.Healthcheck; hc != nil {
+		data.Healthcheck = &format.HealthcheckData{
+			Test:        hc.Test,
+			Interval:    durationString(hc.Interval),
+			Timeout:     durationString(hc.Timeout),
+			StartPeriod: durationString(hc.StartPeriod),
+			Retries:     hc.Retries,
+		}
+	}
+
+	if res.Index != nil {
+		indexDigest, err := res.Index.Digest()
+		if err != nil {
+			return format.InspectData{}, fmt.Errorf("reading index digest: %w", err)
+		}
+		data.Index = indexDigest.String()
+	}
+	return data, nil
+}
+
+// durationString renders a healthcheck duration, leaving zero (the runtime
+// default) empty.
+func durationString(d time.Duration) string {
+	if d == 0 {
+		return ""
+	}
+	return d.String()
 }
@@ -1,15 +1,25 @@
 package commands_test
 
 import (
+	"archive/tar"
 	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/google/go-containerregistry/pkg/logs"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
 	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
 	"github.com/thisisnotashwin/imgutil/internal/image"
 )
 
@@ -22,6 +32,57 @@ func randomImage(t *testing.T) v1.Image {
 	return img
 }
 
+// fileLayer builds an uncompressed-tar layer from path → content. Paths ending
+// in "/" are directories.
+func fileLayer(t *testing.T, files map[string]string) v1.Layer {
+	t.Helper()
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, p := range paths {
+		hdr := &tar.Header{Name: p, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(files[p]))}
+		if strings.HasSuffix(p, "/") {
+			hdr = &tar.Header{Name: p, Mode: 0o755, Typeflag: tar.TypeDir}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[p])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+// layeredImage builds an image from fileLayer specs, bottom layer first.
+func layeredImage(t *testing.T, layers ...map[string]string) v1.Image {
+	t.Helper()
+	img := empty.Image
+	for _, files := range layers {
+		var err error
+		img, err = mutate.AppendLayers(img, fileLayer(t, files))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return img
+}
+
 func daemonLoader(img v1.Image) *image.Loader {
 	return image.NewLoaderWithFetchers(
 		func(_ name.Reference) (v1.Image, error) { return img, nil },
@@ -67,6 +128,75 @@ func TestInspectCmd_JSONOutput(t *testing.T) {
 	}
 }
 
+func TestInspectCmd_FullConfig(t *testing.T) {
+	img := randomImage(t)
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OSVersion = "10.0.17763.1"
+	cfg.Config.User = "app"
+	cfg.Config.WorkingDir = "/srv"
+	cfg.Config.Volumes = map[string]struct{}{"/data": {}}
+	cfg.Config.StopSignal = "SIGQUIT"
+	cfg.Config.Healthcheck = &v1.HealthConfig{Test: []string{"CMD", "true"}, Interval: 30 * time.Second, Retries: 3}
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"inspect", "--output", "json", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.InspectData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got.User != "app" || got.WorkingDir != "/srv" || got.StopSignal != "SIGQUIT" || got.OSVersion != "10.0.17763.1" {
+		t.Errorf("got %+v", got)
+	}
+	if len(got.Volumes) != 1 || got.Volumes[0] != "/data" {
+		t.Errorf("got volumes %v, want [/data]", got.Volumes)
+	}
+	if got.Healthcheck == nil || got.Healthcheck.Interval != "30s" || got.Healthcheck.Retries != 3 {
+		t.Errorf("got healthcheck %+v", got.Healthcheck)
+	}
+}
+
+func TestInspectCmd_Raw(t *testing.T) {
+	img := randomImage(t)
+	wantConfig, err := img.RawConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantManifest, err := img.RawManifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for what, want := range map[string][]byte{"config": wantConfig, "manifest": wantManifest} {
+		root := commands.NewRootCmd(daemonLoader(img))
+		var buf bytes.Buffer
+		root.SetOut(&buf)
+		root.SetErr(&buf)
+		root.SetArgs([]string{"inspect", "--raw", what, "alpine:latest"})
+
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%s: unexpected error: %v\noutput: %s", what, err, buf.String())
+		}
+		if !bytes.Equal(buf.Bytes(), want) {
+			t.Errorf("--raw %s: output differs from the raw bytes\ngot: %s", what, buf.String())
+		}
+	}
+}
+
 func TestInspectCmd_RequiresArgument(t *testing.T) {
 	loader := daemonLoader(randomImage(t))
 	root := commands.NewRootCmd(loader)
@@ -89,3 +219,59 @@ func TestInspectCmd_MutuallyExclusiveFlags(t *testing.T) {
 		t.Error("expected error when --local and --remote both set")
 	}
 }
+
+func TestInspectCmd_Debug(t *testing.T) {
+	t.Cleanup(func() {
+		logs.Warn.SetOutput(io.Discard)
+	})
+	loader := daemonLoader(randomImage(t))
+	root := commands.NewRootCmd(loader)
+
+	var out, errOut bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&errOut)
+	root.SetArgs([]string{"inspect", "--debug", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, errOut.String())
+	}
+	if !strings.Contains(errOut.String(), "loaded alpine:latest from daemon") {
+		t.Errorf("stderr missing loader debug log\ngot: %s", errOut.String())
+	}
+	if strings.Contains(out.String(), "debug:") {
+		t.Errorf("debug log leaked into stdout\ngot: %s", out.String())
+	}
+	if logs.Enabled(logs.Debug) {
+		t.Error("ggcr's debug logger, which does not redact cookies, was enabled")
+	}
+}
+
+func TestInspectCmd_TemplateOutput(t *testing.T) {
+	img := randomImage(t)
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"inspect", "--output", "template={{.Digest}}", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if got, want := buf.String(), digest.String()+"\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestInspectCmd_UnknownOutputFormat(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(randomImage(t)))
+	root.SetArgs([]string{"inspect", "--output", "xml", "alpine:latest"})
+
+	if err := root.Execute(); err == nil {
+		t.Error("expected error for unknown output format")
+	}
+}
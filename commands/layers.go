@@ -2,65 +2,146 @@ package commands
 
 import (
 	"fmt"
-	"strings"
+	"io"
 
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/spf13/cobra"
 	"github.com/thisisnotashwin/imgutil/internal/format"
 	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
 )
 
 func newLayersCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
-	return &cobra.Command{
-		Use:   "layers <image>",
+	var (
+		files bool
+		batch batchFlags
+	)
+
+	cmd := &cobra.Command{
+		Use:   "layers <image>...",
 		Short: "Display per-layer breakdown of an image",
-		Args:  cobra.ExactArgs(1),
+		Long: `Displays each layer of an image with its size, compression and the build
+step that created it.
+
+Several images may be given as arguments or listed with --from-file; they are
+loaded in parallel and reported in input order, one JSON Lines record each
+with --output json. An image that fails to load is reported without stopping
+the rest.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			img, err := loader.Load(args[0], sourceFromFlags(flags))
+			outFmt, err := formatFromFlags(flags)
 			if err != nil {
 				return err
 			}
-
-			layers, err := img.Layers()
+			refs, err := batch.refs(cmd, args)
 			if err != nil {
-				return fmt.Errorf("reading layers: %w", err)
+				return err
 			}
 
-			cfg, err := img.ConfigFile()
-			if err != nil {
-				return fmt.Errorf("reading config: %w", err)
+			if batch.batch(refs) {
+				return runBatch(cmd, refs, batch.concurrency, outFmt,
+					func(ref string) ([]format.LayerData, error) {
+						return imageLayers(loader, ref, sourceFromFlags(flags), files)
+					},
+					func(ref string, layers []format.LayerData) any {
+						return format.ImageLayersData{Reference: ref, Layers: layers}
+					},
+					func(w io.Writer, layers []format.LayerData) error {
+						return format.PrintLayers(w, layers, outFmt)
+					},
+				)
 			}
 
-			layerData := make([]format.LayerData, 0, len(layers))
-			for i, l := range layers {
-				digest, err := l.Digest()
-				if err != nil {
-					return fmt.Errorf("reading layer %d digest: %w", i, err)
-				}
-
-				size, err := l.Size()
-				if err != nil {
-					return fmt.Errorf("reading layer %d size: %w", i, err)
-				}
-
-				createdBy := ""
-				if i < len(cfg.History) {
-					createdBy = cfg.History[i].CreatedBy
-					createdBy = strings.TrimPrefix(createdBy, "|0 /bin/sh -c ")
-					createdBy = strings.TrimPrefix(createdBy, "/bin/sh -c ")
-					if len(createdBy) > 80 {
-						createdBy = createdBy[:77] + "..."
-					}
-				}
-
-				layerData = append(layerData, format.LayerData{
-					Index:   i,
-					Digest:  digest.String(),
-					Size:    size,
-					Command: createdBy,
-				})
+			layerData, err := imageLayers(loader, refs[0], sourceFromFlags(flags), files)
+			if err != nil {
+				return err
 			}
-
-			return format.PrintLayers(cmd.OutOrStdout(), layerData, formatFromFlags(flags))
+			return format.PrintLayers(cmd.OutOrStdout(), layerData, outFmt)
 		},
 	}
+
+	cmd.Flags().BoolVar(&files, "files", false, "List files added, modified and deleted by each layer")
+	batch.register(cmd)
+
+	return cmd
+}
+
+// imageLayers loads ref and describes each of its layers, with the files they
+// change if files is set.
+func imageLayers(loader *image.Loader, ref string, src image.Source, files bool) ([]format.LayerData, error) {
+	img, err := loader.Load(ref, src)
+	if err != nil {
+		return nil, err
+	}
+
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("reading layers: %w", err)
+	}
+
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+
+	manifest, err := img.Manifest()
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	history := layerHistory(cfg)
+
+	var changes [][]layer.Change
+	if files {
+		changes, err = layer.Changes(layers)
+		if err != nil {
+			return nil, fmt.Errorf("reading layer files: %w", err)
+		}
+	}
+
+	layerData := make([]format.LayerData, 0, len(layers))
+	for i, l := range layers {
+		digest, err := l.Digest()
+		if err != nil {
+			return nil, fmt.Errorf("reading layer %d digest: %w", i, err)
+		}
+
+		size, err := l.Size()
+		if err != nil {
+			return nil, fmt.Errorf("reading layer %d size: %w", i, err)
+		}
+
+		createdBy := ""
+		if i < len(history) {
+			createdBy = stepCommand(history[i])
+		}
+		if len(createdBy) > 80 {
+			createdBy = createdBy[:77] + "..."
+		}
+
+		mediaType, err := l.MediaType()
+		if err != nil {
+			return nil, fmt.Errorf("reading layer %d media type: %w", i, err)
+		}
+		desc := v1.Descriptor{MediaType: mediaType}
+		if i < len(manifest.Layers) {
+			desc = manifest.Layers[i]
+		}
+
+		data := format.LayerData{
+			Index:       i,
+			Digest:      digest.String(),
+			Size:        size,
+			MediaType:   string(mediaType),
+			Compression: layerCompression(desc),
+			Command:     createdBy,
+		}
+		if files {
+			data.Files = make([]format.FileChange, 0, len(changes[i]))
+			for _, c := range changes[i] {
+				data.Files = append(data.Files, format.FileChange{Path: c.Path, Kind: string(c.Kind)})
+			}
+		}
+		layerData = append(layerData, data)
+	}
+	return layerData, nil
 }
@@ -55,3 +55,87 @@ func TestLayersCmd_RequiresArgument(t *testing.T) {
 		t.Error("expected error when no image argument provided")
 	}
 }
+
+func TestLayersCmd_Files(t *testing.T) {
+	loader := daemonLoader(randomImage(t))
+	root := commands.NewRootCmd(loader)
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"layers", "--files", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	out := buf.String()
+	if !strings.Contains(out, "Layer 1 files:") {
+		t.Errorf("output missing per-layer file section\ngot: %s", out)
+	}
+	if !strings.Contains(out, "A /random_file_") {
+		t.Errorf("output missing added file\ngot: %s", out)
+	}
+}
+
+func TestLayersCmd_FilesJSON(t *testing.T) {
+	loader := daemonLoader(randomImage(t))
+	root := commands.NewRootCmd(loader)
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"layers", "--files", "--output", "json", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if !strings.Contains(buf.String(), `"kind": "added"`) {
+		t.Errorf("JSON output missing file change kind\ngot: %s", buf.String())
+	}
+}
+
+func TestLayersCmd_FilesJSONEmptyLayer(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--files"}, `"files":[]`},
+		{nil, ""},
+	} {
+		loader := daemonLoader(layeredImage(t, map[string]string{}))
+		root := commands.NewRootCmd(loader)
+
+		var buf bytes.Buffer
+		root.SetOut(&buf)
+		root.SetErr(&buf)
+		root.SetArgs(append(append([]string{"layers"}, tc.args...), "--output", "json", "img"))
+
+		if err := root.Execute(); err != nil {
+			t.Fatalf("%v: unexpected error: %v\noutput: %s", tc.args, err, buf.String())
+		}
+		compact := strings.Join(strings.Fields(buf.String()), "")
+		if tc.want != "" && !strings.Contains(compact, tc.want) {
+			t.Errorf("%v: output missing %s\ngot: %s", tc.args, tc.want, buf.String())
+		}
+		if tc.want == "" && strings.Contains(compact, `"files"`) {
+			t.Errorf("%v: files reported without --files\ngot: %s", tc.args, buf.String())
+		}
+	}
+}
+
+func TestLayersCmd_CSVOutput(t *testing.T) {
+	loader := daemonLoader(randomImage(t))
+	root := commands.NewRootCmd(loader)
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"layers", "--output", "csv", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if !strings.HasPrefix(buf.String(), "index,digest,size,media_type,compression,command") {
+		t.Errorf("CSV output missing header\ngot: %s", buf.String())
+	}
+}
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"archive/tar"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func newLsCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var recursive bool
+
+	cmd := &cobra.Command{
+		Use:   "ls <image> [path]",
+		Short: "List files in the image's merged filesystem",
+		Long: `Lists a directory of the filesystem the image's layers produce once
+whiteouts are applied, with mode, owner, size, modification time and link
+targets. path defaults to /. A path that is not a directory lists just that entry.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			dir := "/"
+			if len(args) == 2 {
+				dir = args[1]
+			}
+
+			merged, err := flattenImage(loader, args[0], flags)
+			if err != nil {
+				return err
+			}
+
+			entry, err := merged.Stat(dir)
+			if err != nil {
+				return err
+			}
+
+			var entries []*layer.Entry
+			switch {
+			case entry.Header.Typeflag != tar.TypeDir:
+				entries = []*layer.Entry{entry}
+			case recursive:
+				prefix := strings.TrimSuffix(entry.Header.Name, "/") + "/"
+				for _, p := range merged.Paths() {
+					if strings.HasPrefix(p, prefix) {
+						e, _ := merged.Lookup(p)
+						entries = append(entries, e)
+					}
+				}
+			default:
+				if entries, err = merged.ReadDir(dir); err != nil {
+					return err
+				}
+			}
+
+			data := make([]format.FileData, 0, len(entries))
+			for _, e := range entries {
+				data = append(data, fileData(e))
+			}
+			return format.PrintFiles(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "List everything beneath path")
+
+	return cmd
+}
+
+// flattenImage loads ref and merges its layers into a single filesystem. File
+// contents are not hashed; diff, which compares them, flattens on its own.
+func flattenImage(loader *image.Loader, ref string, flags *GlobalFlags) (*layer.FS, error) {
+	img, err := loader.Load(ref, sourceFromFlags(flags))
+	if err != nil {
+		return nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, fmt.Errorf("reading layers: %w", err)
+	}
+	merged, err := layer.FlattenHeaders(layers)
+	if err != nil {
+		return nil, fmt.Errorf("reading filesystem: %w", err)
+	}
+	return merged, nil
+}
+
+func fileData(e *layer.Entry) format.FileData {
+	hdr := e.Header
+	owner := hdr.Uname
+	if owner == "" {
+		owner = fmt.Sprint(hdr.Uid)
+	}
+	group := hdr.Gname
+	if group == "" {
+		group = fmt.Sprint(hdr.Gid)
+	}
+	d := format.FileData{
+		Path:     hdr.Name,
+		Type:     fileType(hdr.Typeflag),
+		Mode:     modeString(hdr),
+		UID:      hdr.Uid,
+		GID:      hdr.Gid,
+		Owner:    owner + ":" + group,
+		Size:     hdr.Size,
+		LinkName: hdr.Linkname,
+		Layer:    e.Layer,
+	}
+	if !hdr.ModTime.IsZero() {
+		d.ModTime = hdr.ModTime.UTC().Format("2006-01-02 15:04:05")
+	}
+	return d
+}
+
+func fileType(flag byte) string {
+	switch flag {
+	case tar.TypeDir:
+		return "dir"
+	case tar.TypeSymlink:
+		return "symlink"
+	case tar.TypeLink:
+		return "hardlink"
+	case tar.TypeChar:
+		return "char"
+	case tar.TypeBlock:
+		return "block"
+	case tar.TypeFifo:
+		return "fifo"
+	}
+	return "file"
+}
+
+// modeString renders a tar header's mode the way ls -l does.
+func modeString(hdr *tar.Header) string {
+	mode := hdr.FileInfo().Mode()
+	kind := byte('-')
+	switch {
+	case mode&fs.ModeDir != 0:
+		kind = 'd'
+	case mode&fs.ModeSymlink != 0:
+		kind = 'l'
+	case mode&fs.ModeCharDevice != 0:
+		kind = 'c'
+	case mode&fs.ModeDevice != 0:
+		kind = 'b'
+	case mode&fs.ModeNamedPipe != 0:
+		kind = 'p'
+	}
+
+	perm := []byte(mode.Perm().String()[1:])
+	special := []struct {
+		bit   fs.FileMode
+		index int
+		set   byte
+	}{
+		{fs.ModeSetuid, 2, 's'},
+		{fs.ModeSetgid, 5, 's'},
+		{fs.ModeSticky, 8, 't'},
+	}
+	for _, s := range special {
+		if mode&s.bit == 0 {
+			continue
+		}
+		if perm[s.index] == 'x' {
+			perm[s.index] = s.set
+		} else {
+			perm[s.index] = s.set - 'a' + 'A'
+		}
+	}
+	return string(kind) + string(perm)
+}
@@ -0,0 +1,99 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func TestLsCmd_JSONOutput(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"etc/": "", "etc/hosts": "127.0.0.1", "etc/passwd": "root"},
+		map[string]string{"etc/.wh.passwd": "", "etc/motd": "hi"},
+	)
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"ls", "--output", "json", "app:latest", "/etc"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got []format.FileData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got) != 2 || got[0].Path != "/etc/hosts" || got[1].Path != "/etc/motd" {
+		t.Fatalf("got %+v, want /etc/hosts and /etc/motd", got)
+	}
+	if got[0].Mode != "-rw-r--r--" || got[0].Size != 9 || got[0].Layer != 0 {
+		t.Errorf("got hosts entry %+v", got[0])
+	}
+	if got[1].Layer != 1 {
+		t.Errorf("got motd from layer %d, want 1", got[1].Layer)
+	}
+}
+
+func TestLsCmd_HumanRoot(t *testing.T) {
+	img := layeredImage(t, map[string]string{"etc/": "", "bin/sh": "elf"})
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"ls", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	for _, want := range []string{"drwxr-xr-x", "/bin", "/etc"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot: %s", want, buf.String())
+		}
+	}
+}
+
+func TestCatCmd(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"etc/os-release": "ID=old"},
+		map[string]string{"etc/os-release": "ID=new"},
+	)
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"cat", "app:latest", "/etc/os-release"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if buf.String() != "ID=new" {
+		t.Errorf("got %q, want %q", buf.String(), "ID=new")
+	}
+}
+
+func TestCatCmd_MissingFile(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(layeredImage(t, map[string]string{"etc/": ""})))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"cat", "app:latest", "/etc/missing"})
+
+	err := root.Execute()
+	if err == nil {
+		t.Fatal("expected error for a missing file")
+	}
+	// A missing path is not a missing image, which exits with its own code.
+	if kind := image.KindName(err); kind != "error" {
+		t.Errorf("got kind %q, want error: %v", kind, err)
+	}
+}
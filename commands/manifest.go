@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// estargzTOCAnnotation marks a gzip layer as eStargz (seekable, lazily pulled).
+const estargzTOCAnnotation = "containerd.io/snapshot/stargz/toc.digest"
+
+func newManifestCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "manifest <image>",
+		Short: "Display the image manifest and its descriptors",
+		Long: `Displays the image manifest: its media type (Docker v2 or OCI), schema
+version, config descriptor, and each layer's media type, compression, URLs and
+annotations. For a multi-platform image this is the manifest of the selected
+platform; use index for the index itself.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			img, err := loader.Load(args[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+
+			digest, err := img.Digest()
+			if err != nil {
+				return fmt.Errorf("reading digest: %w", err)
+			}
+			mediaType, err := img.MediaType()
+			if err != nil {
+				return fmt.Errorf("reading media type: %w", err)
+			}
+			manifest, err := img.Manifest()
+			if err != nil {
+				return fmt.Errorf("reading manifest: %w", err)
+			}
+
+			data := format.ImageManifestData{
+				Reference:     args[0],
+				Digest:        digest.String(),
+				MediaType:     string(mediaType),
+				SchemaVersion: manifest.SchemaVersion,
+				Config:        descriptorData(manifest.Config),
+				Layers:        make([]format.DescriptorData, 0, len(manifest.Layers)),
+				Annotations:   manifest.Annotations,
+			}
+			for _, desc := range manifest.Layers {
+				l := descriptorData(desc)
+				l.Compression = layerCompression(desc)
+				l.NonDistributable = nonDistributable(desc.MediaType)
+				data.Layers = append(data.Layers, l)
+			}
+			if manifest.Subject != nil {
+				subject := descriptorData(*manifest.Subject)
+				data.Subject = &subject
+			}
+
+			return format.PrintManifest(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+}
+
+func descriptorData(desc v1.Descriptor) format.DescriptorData {
+	return format.DescriptorData{
+		MediaType:   string(desc.MediaType),
+		Digest:      desc.Digest.String(),
+		Size:        desc.Size,
+		URLs:        desc.URLs,
+		Annotations: desc.Annotations,
+	}
+}
+
+// layerCompression names the compression of a layer blob from its media type,
+// reporting gzip layers that carry an eStargz table of contents as estargz.
+func layerCompression(desc v1.Descriptor) string {
+	mt := string(desc.MediaType)
+	switch {
+	case strings.HasSuffix(mt, "+zstd"):
+		return "zstd"
+	case strings.HasSuffix(mt, "+gzip"), strings.HasSuffix(mt, ".gzip"):
+		if _, ok := desc.Annotations[estargzTOCAnnotation]; ok {
+			return "estargz"
+		}
+		return "gzip"
+	case strings.HasSuffix(mt, ".tar"):
+		return "none"
+	}
+	return "unknown"
+}
+
+// nonDistributable reports whether a layer may not be pushed to other
+// registries: Docker foreign layers and OCI non-distributable layers.
+func nonDistributable(mt types.MediaType) bool {
+	return mt == types.DockerForeignLayer || strings.Contains(string(mt), ".nondistributable.")
+}
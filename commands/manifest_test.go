@@ -0,0 +1,93 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+// mixedLayerImage has one layer of each interesting kind: plain gzip, zstd,
+// eStargz and a non-distributable layer with a URL.
+func mixedLayerImage(t *testing.T) v1.Image {
+	t.Helper()
+	img, err := mutate.Append(mutate.MediaType(empty.Image, types.OCIManifestSchema1),
+		mutate.Addendum{Layer: fileLayer(t, map[string]string{"a": "a"}), MediaType: types.OCILayer},
+		mutate.Addendum{Layer: fileLayer(t, map[string]string{"b": "b"}), MediaType: types.OCILayerZStd},
+		mutate.Addendum{
+			Layer:       fileLayer(t, map[string]string{"c": "c"}),
+			MediaType:   types.OCILayer,
+			Annotations: map[string]string{"containerd.io/snapshot/stargz/toc.digest": "sha256:toc"},
+		},
+		mutate.Addendum{
+			Layer:     fileLayer(t, map[string]string{"d": "d"}),
+			MediaType: types.OCIRestrictedLayer,
+			URLs:      []string{"https://example.com/layer.tar.gz"},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mutate.Annotations(img, map[string]string{"org.opencontainers.image.source": "https://example.com/app"}).(v1.Image)
+}
+
+func TestManifestCmd_JSONOutput(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(mixedLayerImage(t)))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"manifest", "--output", "json", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.ImageManifestData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got.SchemaVersion != 2 || got.MediaType != string(types.OCIManifestSchema1) || got.Config.Digest == "" {
+		t.Errorf("got %+v", got)
+	}
+	if got.Annotations["org.opencontainers.image.source"] != "https://example.com/app" {
+		t.Errorf("got annotations %v", got.Annotations)
+	}
+	want := []string{"gzip", "zstd", "estargz", "gzip"}
+	if len(got.Layers) != len(want) {
+		t.Fatalf("got %d layers, want %d", len(got.Layers), len(want))
+	}
+	for i, c := range want {
+		if got.Layers[i].Compression != c {
+			t.Errorf("layer %d: got compression %q, want %q", i, got.Layers[i].Compression, c)
+		}
+	}
+	last := got.Layers[3]
+	if !last.NonDistributable || len(last.URLs) != 1 {
+		t.Errorf("got last layer %+v, want non-distributable with a URL", last)
+	}
+}
+
+func TestLayersCmd_ShowsCompression(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(mixedLayerImage(t)))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"layers", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	for _, want := range []string{"COMPRESSION", "zstd", "estargz"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot: %s", want, buf.String())
+		}
+	}
+}
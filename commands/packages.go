@@ -0,0 +1,94 @@
+package commands
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+)
+
+func newPackagesCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var types []string
+
+	cmd := &cobra.Command{
+		Use:   "packages <image>",
+		Short: "List the OS packages and language dependencies installed in an image",
+		Long: `Reads the package databases in the image's filesystem (dpkg, including
+distroless status.d entries, apk and rpm) and lists each installed package with
+its version, architecture, source package and the layer that installed it.
+
+Language-level dependencies are listed too: modules compiled into Go binaries
+(type go), Python distributions (python), packages under node_modules (npm)
+and Maven artifacts in JARs, including nested ones (java). Use --type to
+select, e.g. --type deb,apk,rpm for OS packages only.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			for _, t := range types {
+				if !slices.Contains(inventory.Types, t) {
+					return fmt.Errorf("invalid --type %q: want one of %s", t, strings.Join(inventory.Types, ", "))
+				}
+			}
+
+			_, layers, inv, err := scanImage(loader, args[0], flags)
+			if err != nil {
+				return err
+			}
+			pkgs := inv.Packages
+			if len(types) > 0 {
+				pkgs = slices.DeleteFunc(pkgs, func(p inventory.Package) bool {
+					return !slices.Contains(types, p.Type)
+				})
+			}
+
+			digests, err := layerDigests(layers)
+			if err != nil {
+				return err
+			}
+
+			data := make([]format.PackageData, 0, len(pkgs))
+			for _, p := range pkgs {
+				data = append(data, format.PackageData{
+					Name:        p.Name,
+					Version:     p.Version,
+					Arch:        p.Arch,
+					Source:      p.Source,
+					Type:        p.Type,
+					Path:        p.Path,
+					Layer:       p.Layer,
+					LayerDigest: digests[p.Layer],
+				})
+			}
+			return format.PrintPackages(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+
+	cmd.Flags().StringSliceVar(&types, "type", nil, "Only list packages of these types ("+strings.Join(inventory.Types, ", ")+")")
+
+	return cmd
+}
+
+// scanImage loads ref and scans its layers for installed packages.
+func scanImage(loader *image.Loader, ref string, flags *GlobalFlags) (v1.Image, []v1.Layer, *inventory.Inventory, error) {
+	img, err := loader.Load(ref, sourceFromFlags(flags))
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	layers, err := img.Layers()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("reading layers: %w", err)
+	}
+	inv, err := inventory.Scan(layers)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("reading packages: %w", err)
+	}
+	return img, layers, inv, nil
+}
@@ -0,0 +1,99 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+func TestPackagesCmd_JSON(t *testing.T) {
+	base := "Package: libc6\nStatus: install ok installed\nArchitecture: amd64\nVersion: 2.36-9\nSource: glibc\n"
+	img := layeredImage(t,
+		map[string]string{"var/lib/dpkg/status": base},
+		map[string]string{"var/lib/dpkg/status": base + "\nPackage: curl\nStatus: install ok installed\nArchitecture: amd64\nVersion: 7.88.1-10\n"},
+	)
+	layers, err := img.Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := layers[1].Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"packages", "-o", "json", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got []format.PackageData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got) != 2 || got[0].Name != "curl" || got[1].Name != "libc6" {
+		t.Fatalf("got %+v, want curl and libc6", got)
+	}
+	if got[0].Layer != 1 || got[0].LayerDigest != second.String() {
+		t.Errorf("curl attributed to layer %d (%s), want 1 (%s)", got[0].Layer, got[0].LayerDigest, second)
+	}
+	if got[1].Layer != 0 || got[1].Source != "glibc" {
+		t.Errorf("got libc6 %+v, want layer 0 from glibc", got[1])
+	}
+}
+
+func TestPackagesCmd_Human(t *testing.T) {
+	img := layeredImage(t, map[string]string{"lib/apk/db/installed": "P:musl\nV:1.2.4-r2\nA:x86_64\no:musl\n"})
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"packages", "alpine:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	for _, want := range []string{"NAME", "musl", "1.2.4-r2", "apk"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot: %s", want, buf.String())
+		}
+	}
+}
+
+func TestPackagesCmd_TypeFilter(t *testing.T) {
+	img := layeredImage(t, map[string]string{
+		"lib/apk/db/installed":                  "P:musl\nV:1.2.4-r2\nA:x86_64\n",
+		"app/node_modules/lodash/package.json":  `{"name": "lodash", "version": "4.17.21"}`,
+		"app/node_modules/express/package.json": `{"name": "express", "version": "4.19.2"}`,
+	})
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"packages", "--type", "npm", "-o", "json", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got []format.PackageData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got) != 2 || got[0].Name != "express" || got[1].Path != "/app/node_modules/lodash/package.json" {
+		t.Errorf("got %+v, want express and lodash only", got)
+	}
+
+	root.SetArgs([]string{"packages", "--type", "cargo", "app:latest"})
+	if err := root.Execute(); err == nil {
+		t.Error("expected error for unknown --type")
+	}
+}
@@ -1,17 +1,44 @@
 package commands
 
 import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/logs"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/cache"
 	"github.com/thisisnotashwin/imgutil/internal/format"
 	"github.com/thisisnotashwin/imgutil/internal/image"
 )
 
 // GlobalFlags holds flags inherited by all subcommands.
 type GlobalFlags struct {
-	Output string
-	Local  bool
-	Remote bool
-	Debug  bool
+	Output   string
+	Local    bool
+	Remote   bool
+	Debug    bool
+	Platform string
+
+	Cache     bool
+	CacheDir  string
+	CacheSize string
+}
+
+// ErrCheckFailed marks the error of a command whose image failed a check
+// requested for CI, such as analyze --min-efficiency or vulns --fail-on. Match it with errors.Is.
+var ErrCheckFailed = errors.New("check failed")
+
+// checkError is a failed check; its message says which and why.
+type checkError struct{ msg string }
+
+func (e *checkError) Error() string { return e.msg }
+
+func (e *checkError) Is(target error) bool { return target == ErrCheckFailed }
+
+// checkFailed returns an error matching ErrCheckFailed with the given message.
+func checkFailed(format string, args ...any) error {
+	return &checkError{msg: fmt.Sprintf(format, args...)}
 }
 
 // NewRootCmd builds the root cobra command with all subcommands attached.
@@ -22,16 +49,75 @@ func NewRootCmd(loader *image.Loader) *cobra.Command {
 	root := &cobra.Command{
 		Use:   "imgutil",
 		Short: "Inspect Docker images from local daemon or remote registries",
+		// main reports errors itself so it can honour --output json.
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// Arguments have been validated by now; later failures are not
+			// usage errors, so don't bury them under the usage text.
+			cmd.SilenceUsage = true
+
+			if flags.Debug {
+				// ggcr's own debug logger is left off: it dumps whole
+				// requests and responses, redacting only Authorization.
+				// The loader's log covers them with every credential hidden.
+				logs.Warn.SetOutput(cmd.ErrOrStderr())
+				loader.SetDebug(cmd.ErrOrStderr())
+			}
+
+			if flags.Cache || cmd.Flags().Changed("cache-dir") {
+				c, err := cacheFromFlags(flags)
+				if err != nil {
+					return err
+				}
+				// Enforce the size limit before this run adds to the cache.
+				if _, err := c.Prune(c.MaxSize()); err != nil && flags.Debug {
+					_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "debug: cache: %v\n", err)
+				}
+				loader.SetCache(c)
+			}
+
+			if flags.Platform == "" {
+				loader.SetPlatform(nil)
+				return nil
+			}
+			p, err := v1.ParsePlatform(flags.Platform)
+			if err != nil {
+				return fmt.Errorf("invalid --platform %q: %w", flags.Platform, err)
+			}
+			loader.SetPlatform(p)
+			return nil
+		},
 	}
 
-	root.PersistentFlags().StringVarP(&flags.Output, "output", "o", "human", `Output format: "human" or "json"`)
+	root.PersistentFlags().StringVarP(&flags.Output, "output", "o", "human", `Output format: "human", "json", "yaml", "csv", "template=<go template>" or "jsonpath=<expression>"`)
 	root.PersistentFlags().BoolVar(&flags.Local, "local", false, "Only check local Docker daemon")
 	root.PersistentFlags().BoolVar(&flags.Remote, "remote", false, "Only check remote registry")
 	root.PersistentFlags().BoolVar(&flags.Debug, "debug", false, "Enable debug logging")
+	root.PersistentFlags().StringVar(&flags.Platform, "platform", "", `Platform to select from multi-platform images, e.g. "linux/arm64" or "linux/arm/v7"`)
+	root.PersistentFlags().BoolVar(&flags.Cache, "cache", false, "Cache registry manifests, configs and layers on disk")
+	root.PersistentFlags().StringVar(&flags.CacheDir, "cache-dir", "", "Cache directory, enabling the cache (default $XDG_CACHE_HOME/imgutil)")
+	root.PersistentFlags().StringVar(&flags.CacheSize, "cache-size", "10GB", "Size limit of the cache; least recently used entries are evicted beyond it")
 	root.MarkFlagsMutuallyExclusive("local", "remote")
 
 	root.AddCommand(newInspectCmd(loader, flags))
 	root.AddCommand(newLayersCmd(loader, flags))
+	root.AddCommand(newDiffCmd(loader, flags))
+	root.AddCommand(newIndexCmd(loader, flags))
+	root.AddCommand(newAnalyzeCmd(loader, flags))
+	root.AddCommand(newLsCmd(loader, flags))
+	root.AddCommand(newCatCmd(loader, flags))
+	root.AddCommand(newExportCmd(loader, flags))
+	root.AddCommand(newWhichCmd(loader, flags))
+	root.AddCommand(newHistoryCmd(loader, flags))
+	root.AddCommand(newManifestCmd(loader, flags))
+	root.AddCommand(newTagsCmd(loader, flags))
+	root.AddCommand(newCatalogCmd(loader, flags))
+	root.AddCommand(newDigestCmd(loader, flags))
+	root.AddCommand(newPackagesCmd(loader, flags))
+	root.AddCommand(newSbomCmd(loader, flags))
+	root.AddCommand(newVulnsCmd(loader, flags))
+	root.AddCommand(newSecretsCmd(loader, flags))
+	root.AddCommand(newCacheCmd(flags))
 
 	return root
 }
@@ -46,9 +132,22 @@ func sourceFromFlags(flags *GlobalFlags) image.Source {
 	return image.Auto
 }
 
-func formatFromFlags(flags *GlobalFlags) format.Format {
-	if flags.Output == "json" {
-		return format.JSON
+// cacheFromFlags returns the cache selected by --cache-dir and --cache-size.
+func cacheFromFlags(flags *GlobalFlags) (*cache.Cache, error) {
+	dir := flags.CacheDir
+	if dir == "" {
+		var err error
+		if dir, err = cache.DefaultDir(); err != nil {
+			return nil, fmt.Errorf("locating cache directory: %w", err)
+		}
 	}
-	return format.Human
+	size, err := format.ParseSize(flags.CacheSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --cache-size: %w", err)
+	}
+	return cache.New(dir, size), nil
+}
+
+func formatFromFlags(flags *GlobalFlags) (format.Format, error) {
+	return format.Parse(flags.Output)
 }
@@ -0,0 +1,75 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/commands"
+)
+
+func TestSbomCmd_CycloneDX(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"etc/os-release": "ID=debian\nVERSION_ID=\"12\"\n"},
+		map[string]string{"var/lib/dpkg/status": "Package: curl\nStatus: install ok installed\nArchitecture: amd64\nVersion: 7.88.1-10\n"},
+	)
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func() []byte {
+		root := commands.NewRootCmd(daemonLoader(img))
+		var buf bytes.Buffer
+		root.SetOut(&buf)
+		root.SetErr(&buf)
+		root.SetArgs([]string{"sbom", "--format", "cyclonedx-json", "--timestamp", "2024-01-02T03:04:05Z", "app:latest"})
+		if err := root.Execute(); err != nil {
+			t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+		}
+		return buf.Bytes()
+	}
+	out := run()
+	if again := run(); !bytes.Equal(out, again) {
+		t.Errorf("output differs between runs:\n%s\n---\n%s", out, again)
+	}
+
+	var doc struct {
+		Metadata struct {
+			Timestamp string `json:"timestamp"`
+			Component struct {
+				Version string `json:"version"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			PURL string `json:"purl"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(out, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, out)
+	}
+	if doc.Metadata.Timestamp != "2024-01-02T03:04:05Z" || doc.Metadata.Component.Version != digest.String() {
+		t.Errorf("got metadata %+v, want the fixed timestamp and image digest %s", doc.Metadata, digest)
+	}
+	var purls []string
+	for _, c := range doc.Components {
+		if c.PURL != "" {
+			purls = append(purls, c.PURL)
+		}
+	}
+	if want := "pkg:deb/debian/curl@7.88.1-10?arch=amd64&distro=debian-12"; len(purls) != 1 || purls[0] != want {
+		t.Errorf("got purls %q, want [%s]", purls, want)
+	}
+}
+
+func TestSbomCmd_InvalidFormat(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(randomImage(t)))
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"sbom", "--format", "spdx-tag-value", "app:latest"})
+
+	if err := root.Execute(); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,78 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/secrets"
+)
+
+func newSecretsCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "secrets <image>",
+		Short: "Find credentials left in the image's layers, environment and history",
+		Long: `Streams every layer and scans its text files for private keys, cloud
+provider credentials, API tokens, registry auth in .npmrc and
+.docker/config.json, and high-entropy values assigned to secret-like names.
+Files a later layer deleted are scanned too, since they remain readable in
+their layer; such findings are marked removed.
+
+The image's environment variables and history are also scanned, catching
+build arguments such as NPM_TOKEN that the builder records in each RUN step.
+
+Secrets are redacted in the output. Files over 8 MiB and binary files are
+skipped.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			img, err := loader.Load(args[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+			cfg, err := img.ConfigFile()
+			if err != nil {
+				return fmt.Errorf("reading config: %w", err)
+			}
+			layers, err := img.Layers()
+			if err != nil {
+				return fmt.Errorf("reading layers: %w", err)
+			}
+			digests, err := layerDigests(layers)
+			if err != nil {
+				return err
+			}
+
+			findings, err := secrets.ScanLayers(layers)
+			if err != nil {
+				return fmt.Errorf("scanning layers: %w", err)
+			}
+			findings = append(findings, secrets.ScanConfig(cfg)...)
+
+			data := make([]format.SecretData, 0, len(findings))
+			for _, f := range findings {
+				d := format.SecretData{
+					RuleID:      f.RuleID,
+					Description: f.Description,
+					Source:      f.Source,
+					Location:    f.Location,
+					Path:        f.Path,
+					Line:        f.Line,
+					Layer:       f.Layer,
+					Removed:     f.Removed,
+					Snippet:     f.Snippet,
+				}
+				if f.Layer >= 0 && f.Layer < len(digests) {
+					d.LayerDigest = digests[f.Layer]
+				}
+				data = append(data, d)
+			}
+			return format.PrintSecrets(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+}
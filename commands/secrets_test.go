@@ -0,0 +1,54 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+func TestSecretsCmd_JSON(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"root/.docker/config.json": `{"auths":{"registry.example.com":{"auth":"dXNlcjpwYXNzd29yZA=="}}}`},
+		map[string]string{"root/.docker/.wh.config.json": ""},
+	)
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.Config.Env = []string{"API_KEY=0123456789"}
+	if img, err = mutate.ConfigFile(img, cfg); err != nil {
+		t.Fatal(err)
+	}
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"secrets", "-o", "json", "app:latest"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if strings.Contains(buf.String(), "dXNlcjpwYXNzd29yZA") || strings.Contains(buf.String(), "0123456789") {
+		t.Errorf("output leaks a secret: %s", buf.String())
+	}
+	var got []format.SecretData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want the docker auth and the env var", got)
+	}
+	if f := got[0]; f.RuleID != "docker-config-auth" || f.Layer != 0 || !f.Removed || f.LayerDigest == "" {
+		t.Errorf("got %+v, want removed docker-config-auth in layer 0", f)
+	}
+	if f := got[1]; f.Source != "env" || f.Location != "ENV API_KEY" || f.Layer != -1 {
+		t.Errorf("got %+v, want the API_KEY env var", f)
+	}
+}
@@ -0,0 +1,95 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+func newTagsCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var (
+		filter string
+		order  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "tags <repository>",
+		Short: "List the tags of a registry repository",
+		Long: `Lists the tags of a repository such as ghcr.io/org/app, following the
+registry's pagination. --filter keeps tags matching a regular expression.
+--sort semver orders semantic-version tags (v1, 1.2, 1.2.3-rc.1) by precedence,
+followed by any other tags by name.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			if flags.Local {
+				return errors.New("tags can only be listed from a remote registry")
+			}
+			if order != "registry" && order != "semver" {
+				return fmt.Errorf("invalid --sort %q: want registry or semver", order)
+			}
+			var re *regexp.Regexp
+			if filter != "" {
+				if re, err = regexp.Compile(filter); err != nil {
+					return fmt.Errorf("invalid --filter: %w", err)
+				}
+			}
+
+			all, err := loader.ListTags(args[0])
+			if err != nil {
+				return err
+			}
+
+			tags := make([]string, 0, len(all))
+			for _, t := range all {
+				if re == nil || re.MatchString(t) {
+					tags = append(tags, t)
+				}
+			}
+			if order == "semver" {
+				sortSemver(tags)
+			}
+
+			return format.PrintTags(cmd.OutOrStdout(), format.TagsData{Repository: args[0], Tags: tags}, outFmt)
+		},
+	}
+
+	cmd.Flags().StringVar(&filter, "filter", "", "Only list tags matching this regular expression")
+	cmd.Flags().StringVar(&order, "sort", "registry", `Tag order: "registry" or "semver"`)
+
+	return cmd
+}
+
+// sortSemver orders semantic-version tags by precedence, oldest first, and
+// puts every other tag after them in name order.
+func sortSemver(tags []string) {
+	parsed := make(map[string]version.Semver, len(tags))
+	for _, t := range tags {
+		if v, err := version.ParseSemver(t); err == nil {
+			parsed[t] = v
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool {
+		vi, okI := parsed[tags[i]]
+		vj, okJ := parsed[tags[j]]
+		switch {
+		case okI && okJ:
+			if c := vi.Compare(vj); c != 0 {
+				return c < 0
+			}
+			return tags[i] < tags[j]
+		case okI != okJ:
+			return okI
+		}
+		return tags[i] < tags[j]
+	})
+}
@@ -0,0 +1,129 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// pushTags writes the same random image to repo under each tag.
+func pushTags(t *testing.T, repo string, tags ...string) {
+	t.Helper()
+	img := randomImage(t)
+	for _, tag := range tags {
+		ref, err := name.ParseReference(repo + ":" + tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := remote.Write(ref, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTagsCmd_SemverSort(t *testing.T) {
+	repo := startRegistry(t) + "/app"
+	pushTags(t, repo, "latest", "v1.10.0", "v1.2.0", "1.2.0-rc.1", "v2", "edge")
+	root := commands.NewRootCmd(image.NewLoader())
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"tags", "--sort", "semver", "--output", "json", repo})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.TagsData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	want := []string{"1.2.0-rc.1", "v1.2.0", "v1.10.0", "v2", "edge", "latest"}
+	if strings.Join(got.Tags, " ") != strings.Join(want, " ") {
+		t.Errorf("got tags %v, want %v", got.Tags, want)
+	}
+}
+
+func TestTagsCmd_Filter(t *testing.T) {
+	repo := startRegistry(t) + "/app"
+	pushTags(t, repo, "1.0-alpine", "1.0-debian", "2.0-alpine")
+	root := commands.NewRootCmd(image.NewLoader())
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"tags", "--filter", "-alpine$", repo})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	if got := buf.String(); got != "1.0-alpine\n2.0-alpine\n" {
+		t.Errorf("got %q, want the two alpine tags", got)
+	}
+}
+
+func TestCatalogCmd(t *testing.T) {
+	reg := startRegistry(t)
+	for _, repo := range []string{"team/b", "team/a", "tools/c"} {
+		pushTags(t, reg+"/"+repo, "latest")
+	}
+
+	root := commands.NewRootCmd(image.NewLoader())
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"catalog", "--output", "json", reg})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got format.CatalogData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if strings.Join(got.Repositories, " ") != "team/a team/b tools/c" || got.Next != "" {
+		t.Errorf("got %+v, want all three repositories and no next page", got)
+	}
+
+	root = commands.NewRootCmd(image.NewLoader())
+	buf.Reset()
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"catalog", "--page-size", "2", "--output", "json", reg})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	got = format.CatalogData{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if len(got.Repositories) != 2 || got.Next == "" {
+		t.Errorf("got %+v, want a full page of 2 with a next marker", got)
+	}
+}
+
+func TestCatalogCmd_LastRequiresPageSize(t *testing.T) {
+	root := commands.NewRootCmd(image.NewLoader())
+	root.SetArgs([]string{"catalog", "--last", "team/a", "registry.example.com"})
+
+	err := root.Execute()
+	if err == nil || !strings.Contains(err.Error(), "--last requires --page-size") {
+		t.Errorf("got %v, want an error asking for --page-size", err)
+	}
+}
+
+func TestTagsCmd_RejectsLocal(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(randomImage(t)))
+	root.SetArgs([]string{"tags", "--local", "alpine"})
+	if err := root.Execute(); err == nil {
+		t.Fatal("expected error listing tags with --local")
+	}
+}
@@ -0,0 +1,107 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/vulns"
+)
+
+func newVulnsCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	var (
+		dbPath   string
+		severity string
+		failOn   string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "vulns <image>",
+		Short: "Match the image's packages against a local vulnerability database",
+		Long: `Scans the image like the packages command and matches each package against
+advisories in OSV format read from --db: a directory of OSV JSON files, a zip
+archive such as the bulk exports at https://osv-vulnerabilities.storage.googleapis.com,
+or a single JSON file. No network access is needed.
+
+Versions are compared with each ecosystem's rules: dpkg for Debian and Ubuntu
+(epochs and "~"), apk for Alpine, rpm for Red Hat-style distributions, PEP 440
+for Python, Maven's ordering for Java and semantic versioning for Go and npm.
+Distribution advisories apply only to the image's release, as read from its
+os-release file.
+
+--severity hides findings below a level. With --fail-on the command fails with
+exit code 5 when any finding is at or above the given level, whether shown or
+not, for use as a CI gate.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+			minSeverity, err := vulns.ParseSeverity(severity)
+			if err != nil {
+				return fmt.Errorf("invalid --severity: %w", err)
+			}
+			var failSeverity vulns.Severity
+			if failOn != "" {
+				if failSeverity, err = vulns.ParseSeverity(failOn); err != nil {
+					return fmt.Errorf("invalid --fail-on: %w", err)
+				}
+			}
+
+			db, err := vulns.Load(dbPath)
+			if err != nil {
+				return err
+			}
+			_, layers, inv, err := scanImage(loader, args[0], flags)
+			if err != nil {
+				return err
+			}
+			digests, err := layerDigests(layers)
+			if err != nil {
+				return err
+			}
+
+			findings := db.Match(inv)
+			data := make([]format.VulnData, 0, len(findings))
+			failing := 0
+			for _, f := range findings {
+				if failOn != "" && f.Severity >= failSeverity {
+					failing++
+				}
+				if f.Severity < minSeverity {
+					continue
+				}
+				data = append(data, format.VulnData{
+					ID:           f.ID,
+					Aliases:      f.Aliases,
+					Severity:     f.Severity.String(),
+					Package:      f.Package.Name,
+					Version:      f.Package.Version,
+					FixedVersion: f.Fixed,
+					Type:         f.Package.Type,
+					Path:         f.Package.Path,
+					Layer:        f.Package.Layer,
+					LayerDigest:  digests[f.Package.Layer],
+					Summary:      f.Summary,
+				})
+			}
+
+			if err := format.PrintVulns(cmd.OutOrStdout(), data, outFmt); err != nil {
+				return err
+			}
+			if failing > 0 {
+				return checkFailed("%d vulnerabilities at or above --fail-on %s", failing, failSeverity)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&dbPath, "db", "", "OSV advisory database: a directory, zip archive or JSON file")
+	cmd.Flags().StringVar(&severity, "severity", "", "Only report findings at or above this severity (low, medium, high, critical)")
+	cmd.Flags().StringVar(&failOn, "fail-on", "", "Fail if any finding is at or above this severity")
+	_ = cmd.MarkFlagRequired("db")
+
+	return cmd
+}
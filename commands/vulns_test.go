@@ -0,0 +1,94 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+const vulnsAdvisories = `[
+  {
+    "id": "ALPINE-CVE-2024-0001",
+    "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+    "affected": [{
+      "package": {"ecosystem": "Alpine:v3.19", "name": "musl"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.2.4-r3"}]}]
+    }]
+  },
+  {
+    "id": "ALPINE-CVE-2024-0002",
+    "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:L/AC:H/PR:H/UI:R/S:U/C:L/I:N/A:N"}],
+    "affected": [{
+      "package": {"ecosystem": "Alpine:v3.19", "name": "musl"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.2.4_p1-r0"}]}]
+    }]
+  },
+  {
+    "id": "ALPINE-CVE-2024-0003",
+    "affected": [{
+      "package": {"ecosystem": "Alpine:v3.18", "name": "musl"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "1.2.5-r0"}]}]
+    }]
+  }
+]`
+
+func runVulns(t *testing.T, args ...string) ([]format.VulnData, error) {
+	t.Helper()
+	db := filepath.Join(t.TempDir(), "osv.json")
+	if err := os.WriteFile(db, []byte(vulnsAdvisories), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	img := layeredImage(t, map[string]string{
+		"etc/os-release":       "ID=alpine\nVERSION_ID=3.19.1\n",
+		"lib/apk/db/installed": "P:musl\nV:1.2.4-r2\nA:x86_64\no:musl\n",
+	})
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&bytes.Buffer{})
+	root.SetArgs(append([]string{"vulns", "-o", "json", "--db", db, "alpine:3.19"}, args...))
+	err := root.Execute()
+
+	var got []format.VulnData
+	if jsonErr := json.Unmarshal(out.Bytes(), &got); jsonErr != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", jsonErr, out.String())
+	}
+	return got, err
+}
+
+func TestVulnsCmd_JSON(t *testing.T) {
+	got, err := runVulns(t)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %+v, want the two v3.19 advisories", got)
+	}
+	if got[0].ID != "ALPINE-CVE-2024-0001" || got[0].Severity != "critical" || got[0].FixedVersion != "1.2.4-r3" {
+		t.Errorf("got %+v, want the critical advisory first, fixed in 1.2.4-r3", got[0])
+	}
+	if got[1].Severity != "low" || got[1].Package != "musl" || got[1].LayerDigest == "" {
+		t.Errorf("got %+v, want a low finding on musl with its layer", got[1])
+	}
+}
+
+func TestVulnsCmd_SeverityAndFailOn(t *testing.T) {
+	got, err := runVulns(t, "--severity", "high", "--fail-on", "high")
+	if len(got) != 1 || got[0].Severity != "critical" {
+		t.Errorf("got %+v, want only the critical finding", got)
+	}
+	if err == nil || !strings.Contains(err.Error(), "1 vulnerabilities") {
+		t.Errorf("got error %v, want --fail-on to fail on one finding", err)
+	}
+	if !errors.Is(err, commands.ErrCheckFailed) {
+		t.Errorf("got %v, want an error matching ErrCheckFailed", err)
+	}
+}
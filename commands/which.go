@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func newWhichCmd(loader *image.Loader, flags *GlobalFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "which <image> <path>",
+		Short: "Show which layers added, modified or deleted a path",
+		Long: `Walks the image's layers in order and reports every layer that added,
+modified or deleted path, with the Dockerfile step that created it. path may be
+a glob such as /usr/lib/*.so. A literal path also matches the deletion of one
+of its parent directories.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			outFmt, err := formatFromFlags(flags)
+			if err != nil {
+				return err
+			}
+
+			pattern := layer.Clean(args[1])
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("invalid path pattern %q: %w", args[1], err)
+			}
+
+			img, err := loader.Load(args[0], sourceFromFlags(flags))
+			if err != nil {
+				return err
+			}
+			layers, err := img.Layers()
+			if err != nil {
+				return fmt.Errorf("reading layers: %w", err)
+			}
+			cfg, err := img.ConfigFile()
+			if err != nil {
+				return fmt.Errorf("reading config: %w", err)
+			}
+			changes, err := layer.Changes(layers)
+			if err != nil {
+				return fmt.Errorf("reading layer files: %w", err)
+			}
+
+			history := layerHistory(cfg)
+
+			var data []format.WhichData
+			for i, list := range changes {
+				var digest string
+				for _, c := range list {
+					if !matchesPath(pattern, c) {
+						continue
+					}
+					if digest == "" {
+						d, err := layers[i].Digest()
+						if err != nil {
+							return fmt.Errorf("reading layer %d digest: %w", i, err)
+						}
+						digest = d.String()
+					}
+					var command string
+					if i < len(history) {
+						command = stepCommand(history[i])
+					}
+					data = append(data, format.WhichData{
+						Layer:   i,
+						Digest:  digest,
+						Path:    c.Path,
+						Kind:    string(c.Kind),
+						Command: command,
+					})
+				}
+			}
+			if len(data) == 0 {
+				return fmt.Errorf("no layer of %s touches %s: %w", args[0], args[1], layer.ErrNotExist)
+			}
+
+			return format.PrintWhich(cmd.OutOrStdout(), data, outFmt)
+		},
+	}
+}
+
+// matchesPath reports whether change c concerns pattern. Deleting a directory
+// removes everything beneath it, so a literal path also matches the deletion
+// of any of its parents.
+func matchesPath(pattern string, c layer.Change) bool {
+	if ok, _ := path.Match(pattern, c.Path); ok {
+		return true
+	}
+	return c.Kind == layer.Deleted && strings.HasPrefix(pattern, c.Path+"/")
+}
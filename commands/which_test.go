@@ -0,0 +1,100 @@
+package commands_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/thisisnotashwin/imgutil/commands"
+	"github.com/thisisnotashwin/imgutil/internal/format"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func TestWhichCmd_JSONOutput(t *testing.T) {
+	img, err := mutate.Append(empty.Image,
+		mutate.Addendum{
+			Layer:   fileLayer(t, map[string]string{"app/": "", "app/big.bin": "v1", "app/lib.so": "x"}),
+			History: v1.History{CreatedBy: "/bin/sh -c make"},
+		},
+		mutate.Addendum{
+			Layer:   fileLayer(t, map[string]string{"app/big.bin": "v2"}),
+			History: v1.History{CreatedBy: "/bin/sh -c make again"},
+		},
+		mutate.Addendum{
+			Layer:   fileLayer(t, map[string]string{".wh.app": ""}),
+			History: v1.History{CreatedBy: "/bin/sh -c rm -rf /app"},
+		},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"which", "--output", "json", "app:latest", "/app/big.bin"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	var got []format.WhichData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	want := []format.WhichData{
+		{Layer: 0, Path: "/app/big.bin", Kind: "added", Command: "make"},
+		{Layer: 1, Path: "/app/big.bin", Kind: "modified", Command: "make again"},
+		{Layer: 2, Path: "/app", Kind: "deleted", Command: "rm -rf /app"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	for i := range want {
+		got[i].Digest = ""
+		if got[i] != want[i] {
+			t.Errorf("match %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWhichCmd_Glob(t *testing.T) {
+	img := layeredImage(t,
+		map[string]string{"usr/lib/a.so": "a", "usr/lib/b.so": "b", "usr/lib/c.txt": "c"},
+	)
+	root := commands.NewRootCmd(daemonLoader(img))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"which", "app:latest", "/usr/lib/*.so"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("unexpected error: %v\noutput: %s", err, buf.String())
+	}
+	out := buf.String()
+	if !strings.Contains(out, "/usr/lib/a.so") || !strings.Contains(out, "/usr/lib/b.so") || strings.Contains(out, "c.txt") {
+		t.Errorf("unexpected matches\ngot: %s", out)
+	}
+}
+
+func TestWhichCmd_NoMatch(t *testing.T) {
+	root := commands.NewRootCmd(daemonLoader(layeredImage(t, map[string]string{"etc/hosts": "a"})))
+
+	var buf bytes.Buffer
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"which", "app:latest", "/missing"})
+
+	err := root.Execute()
+	if err == nil {
+		t.Fatal("expected error when no layer touches the path")
+	}
+	if kind := image.KindName(err); kind != "error" {
+		t.Errorf("got kind %q, want error: %v", kind, err)
+	}
+}
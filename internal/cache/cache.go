@@ -0,0 +1,232 @@
+// Package cache is an on-disk, content-addressed store for registry
+// manifests, config blobs and layers. Entries are evicted least recently used
+// first once the store grows past its size limit.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// staleTemp is how old an unfinished download must be before Prune removes it.
+const staleTemp = time.Hour
+
+// Cache is a content-addressed blob store rooted at a directory. Blobs live
+// at blobs/<algorithm>/<hex>; a blob's modification time records when it was
+// last used.
+type Cache struct {
+	dir     string
+	maxSize int64
+}
+
+// New returns a cache rooted at dir that Prune keeps under maxSize bytes. The
+// directory is created on first write.
+func New(dir string, maxSize int64) *Cache {
+	return &Cache{dir: dir, maxSize: maxSize}
+}
+
+// DefaultDir returns $XDG_CACHE_HOME/imgutil, falling back to the platform's
+// user cache directory when XDG_CACHE_HOME is unset.
+func DefaultDir() (string, error) {
+	if d := os.Getenv("XDG_CACHE_HOME"); d != "" {
+		return filepath.Join(d, "imgutil"), nil
+	}
+	d, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "imgutil"), nil
+}
+
+// Dir returns the cache's root directory.
+func (c *Cache) Dir() string { return c.dir }
+
+// MaxSize returns the size limit Prune enforces by default.
+func (c *Cache) MaxSize() int64 { return c.maxSize }
+
+func (c *Cache) path(h v1.Hash) string {
+	return filepath.Join(c.dir, "blobs", h.Algorithm, h.Hex)
+}
+
+// Get returns the contents of blob h and marks it as recently used. It
+// returns an error wrapping fs.ErrNotExist on a miss.
+func (c *Cache) Get(h v1.Hash) ([]byte, error) {
+	p := c.path(h)
+	b, err := os.ReadFile(p) //nolint:gosec // G304: p is derived from a digest
+	if err != nil {
+		return nil, err
+	}
+	c.touch(p)
+	return b, nil
+}
+
+// Put stores b as blob h. It refuses contents that do not match h.
+func (c *Cache) Put(h v1.Hash, b []byte) error {
+	w, err := c.create(h)
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(b); err != nil {
+		w.abort()
+		return err
+	}
+	return w.commit()
+}
+
+// touch records that the blob at p was just used.
+func (c *Cache) touch(p string) {
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+}
+
+// blobWriter stages a blob in a temporary file and moves it into place only
+// once its digest has been verified, so readers never see partial blobs.
+type blobWriter struct {
+	f    *os.File
+	h    hash.Hash
+	want v1.Hash
+	dst  string
+}
+
+func (c *Cache) create(h v1.Hash) (*blobWriter, error) {
+	if h.Algorithm != "sha256" {
+		return nil, fmt.Errorf("unsupported digest algorithm %q", h.Algorithm)
+	}
+	tmp := filepath.Join(c.dir, "tmp")
+	if err := os.MkdirAll(tmp, 0o700); err != nil {
+		return nil, err
+	}
+	f, err := os.CreateTemp(tmp, h.Hex+"-*")
+	if err != nil {
+		return nil, err
+	}
+	return &blobWriter{f: f, h: sha256.New(), want: h, dst: c.path(h)}, nil
+}
+
+func (w *blobWriter) Write(p []byte) (int, error) {
+	w.h.Write(p)
+	return w.f.Write(p)
+}
+
+func (w *blobWriter) commit() error {
+	if err := w.f.Close(); err != nil {
+		_ = os.Remove(w.f.Name())
+		return err
+	}
+	if got := hex.EncodeToString(w.h.Sum(nil)); got != w.want.Hex {
+		_ = os.Remove(w.f.Name())
+		return fmt.Errorf("blob %s: content has digest sha256:%s", w.want, got)
+	}
+	if err := os.MkdirAll(filepath.Dir(w.dst), 0o700); err != nil {
+		_ = os.Remove(w.f.Name())
+		return err
+	}
+	return os.Rename(w.f.Name(), w.dst)
+}
+
+func (w *blobWriter) abort() {
+	_ = w.f.Close()
+	_ = os.Remove(w.f.Name())
+}
+
+// Stats summarises the blobs in a cache.
+type Stats struct {
+	Entries int
+	Size    int64
+}
+
+type blob struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// Stats reports how many blobs the cache holds and their total size.
+func (c *Cache) Stats() (Stats, error) {
+	blobs, err := c.blobs()
+	if err != nil {
+		return Stats{}, err
+	}
+	var s Stats
+	for _, b := range blobs {
+		s.Entries++
+		s.Size += b.size
+	}
+	return s, nil
+}
+
+// Prune evicts the least recently used blobs until the cache holds at most
+// maxSize bytes, and removes abandoned partial downloads. It reports what was
+// removed.
+func (c *Cache) Prune(maxSize int64) (Stats, error) {
+	var removed Stats
+	c.pruneTemp()
+
+	blobs, err := c.blobs()
+	if err != nil {
+		return removed, err
+	}
+	var total int64
+	for _, b := range blobs {
+		total += b.size
+	}
+	sort.Slice(blobs, func(i, j int) bool { return blobs[i].modTime.Before(blobs[j].modTime) })
+	for _, b := range blobs {
+		if total <= maxSize {
+			break
+		}
+		if err := os.Remove(b.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		total -= b.size
+		removed.Entries++
+		removed.Size += b.size
+	}
+	return removed, nil
+}
+
+func (c *Cache) blobs() ([]blob, error) {
+	var blobs []blob
+	err := filepath.WalkDir(filepath.Join(c.dir, "blobs"), func(p string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		blobs = append(blobs, blob{path: p, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading cache: %w", err)
+	}
+	return blobs, nil
+}
+
+// pruneTemp removes staged blobs left behind by interrupted downloads.
+func (c *Cache) pruneTemp() {
+	tmp := filepath.Join(c.dir, "tmp")
+	entries, err := os.ReadDir(tmp)
+	if err != nil {
+		return
+	}
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && time.Since(info.ModTime()) > staleTemp {
+			_ = os.Remove(filepath.Join(tmp, e.Name()))
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package cache_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/thisisnotashwin/imgutil/internal/cache"
+)
+
+func digestOf(b []byte) v1.Hash {
+	sum := sha256.Sum256(b)
+	return v1.Hash{Algorithm: "sha256", Hex: hex.EncodeToString(sum[:])}
+}
+
+func TestCache_PutGet(t *testing.T) {
+	c := cache.New(t.TempDir(), 1<<20)
+	b := []byte(`{"hello":"world"}`)
+	h := digestOf(b)
+
+	if _, err := c.Get(h); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("got %v before Put, want ErrNotExist", err)
+	}
+	if err := c.Put(h, b); err != nil {
+		t.Fatal(err)
+	}
+	got, err := c.Get(h)
+	if err != nil || string(got) != string(b) {
+		t.Errorf("got %q, %v; want %q", got, err, b)
+	}
+	if err := c.Put(digestOf([]byte("other")), b); err == nil {
+		t.Error("expected error storing content under the wrong digest")
+	}
+}
+
+func TestCache_PruneEvictsLeastRecentlyUsed(t *testing.T) {
+	dir := t.TempDir()
+	c := cache.New(dir, 10)
+	blobs := [][]byte{[]byte("aaaaaa"), []byte("bbbbbb"), []byte("cccccc")}
+	for i, b := range blobs {
+		h := digestOf(b)
+		if err := c.Put(h, b); err != nil {
+			t.Fatal(err)
+		}
+		when := time.Now().Add(time.Duration(i-10) * time.Minute)
+		if err := os.Chtimes(filepath.Join(dir, "blobs", "sha256", h.Hex), when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Reading the oldest blob makes it the most recently used.
+	if _, err := c.Get(digestOf(blobs[0])); err != nil {
+		t.Fatal(err)
+	}
+
+	removed, err := c.Prune(c.MaxSize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed.Entries != 2 || removed.Size != 12 {
+		t.Errorf("removed %+v, want 2 entries of 12 bytes", removed)
+	}
+	if _, err := c.Get(digestOf(blobs[0])); err != nil {
+		t.Errorf("most recently used blob was evicted: %v", err)
+	}
+	stats, err := c.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Entries != 1 || stats.Size != 6 {
+		t.Errorf("got %+v after prune, want 1 entry of 6 bytes", stats)
+	}
+}
+
+func TestCache_ImageCachesLayersReadInFull(t *testing.T) {
+	c := cache.New(t.TempDir(), 1<<30)
+	img, err := random.Image(1024, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	layers, err := c.Image(img).Layers()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Read the first layer in full and abandon the second part way.
+	rc, err := layers[0].Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(io.Discard, rc); err != nil {
+		t.Fatal(err)
+	}
+	_ = rc.Close()
+	rc, err = layers[1].Compressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.CopyN(io.Discard, rc, 10); err != nil {
+		t.Fatal(err)
+	}
+	_ = rc.Close()
+
+	for i, want := range []bool{true, false} {
+		h, err := layers[i].Digest()
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = c.Get(h)
+		if got := err == nil; got != want {
+			t.Errorf("layer %d cached = %v, want %v", i, got, want)
+		}
+	}
+
+	// The config is cached once read.
+	if _, err := c.Image(img).ConfigFile(); err != nil {
+		t.Fatal(err)
+	}
+	h, err := img.ConfigName()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(h); err != nil {
+		t.Errorf("config not cached: %v", err)
+	}
+}
@@ -0,0 +1,176 @@
+package cache
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	ggcrcache "github.com/google/go-containerregistry/pkg/v1/cache"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// Image wraps img so its config blob and layers are read from the cache when
+// present and stored as they are downloaded. Layers are cached compressed
+// by digest and uncompressed by diff ID, whichever form is read.
+func (c *Cache) Image(img v1.Image) v1.Image {
+	return ggcrcache.Image(&configImage{Image: img, c: c}, layerCache{c})
+}
+
+// configImage caches an image's config blob by its digest.
+type configImage struct {
+	v1.Image
+	c *Cache
+}
+
+// ConfigName reads the config digest from the manifest; remote images
+// otherwise compute it by downloading the config.
+func (i *configImage) ConfigName() (v1.Hash, error) {
+	m, err := i.Manifest()
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	return m.Config.Digest, nil
+}
+
+func (i *configImage) RawConfigFile() ([]byte, error) {
+	h, err := i.ConfigName()
+	if err != nil {
+		return nil, err
+	}
+	if b, err := i.c.Get(h); err == nil {
+		return b, nil
+	}
+	b, err := i.Image.RawConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	_ = i.c.Put(h, b)
+	return b, nil
+}
+
+func (i *configImage) ConfigFile() (*v1.ConfigFile, error) {
+	return partial.ConfigFile(i)
+}
+
+// Layers wraps the image's layers so their diff IDs come from the cached
+// config rather than another download of it.
+func (i *configImage) Layers() ([]v1.Layer, error) {
+	ls, err := i.Image.Layers()
+	if err != nil {
+		return nil, err
+	}
+	for idx, l := range ls {
+		ls[idx] = &configLayer{Layer: l, img: i}
+	}
+	return ls, nil
+}
+
+type configLayer struct {
+	v1.Layer
+	img *configImage
+}
+
+func (l *configLayer) DiffID() (v1.Hash, error) {
+	h, err := l.Digest()
+	if err != nil {
+		return v1.Hash{}, err
+	}
+	return partial.BlobToDiffID(l.img, h)
+}
+
+// layerCache implements ggcr's layer cache on top of the blob store. Unlike
+// ggcr's filesystem cache it only keeps layers that were read in full and
+// match their digest.
+type layerCache struct{ c *Cache }
+
+func (lc layerCache) Put(l v1.Layer) (v1.Layer, error) {
+	return &cachingLayer{Layer: l, c: lc.c}, nil
+}
+
+func (lc layerCache) Get(h v1.Hash) (v1.Layer, error) {
+	p := lc.c.path(h)
+	if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+		return nil, ggcrcache.ErrNotFound
+	}
+	lc.c.touch(p)
+	return tarball.LayerFromFile(p)
+}
+
+func (lc layerCache) Delete(h v1.Hash) error {
+	err := os.Remove(lc.c.path(h))
+	if errors.Is(err, fs.ErrNotExist) {
+		return ggcrcache.ErrNotFound
+	}
+	return err
+}
+
+// cachingLayer stores a layer's contents as they are streamed.
+type cachingLayer struct {
+	v1.Layer
+	c *Cache
+}
+
+func (l *cachingLayer) Compressed() (io.ReadCloser, error) {
+	h, err := l.Digest()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := l.Layer.Compressed()
+	if err != nil {
+		return nil, err
+	}
+	return l.c.tee(h, rc), nil
+}
+
+func (l *cachingLayer) Uncompressed() (io.ReadCloser, error) {
+	h, err := l.DiffID()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := l.Layer.Uncompressed()
+	if err != nil {
+		return nil, err
+	}
+	return l.c.tee(h, rc), nil
+}
+
+// tee copies rc into blob h as it is read. The blob is kept only if rc is
+// read to the end; failing to cache never fails the read.
+func (c *Cache) tee(h v1.Hash, rc io.ReadCloser) io.ReadCloser {
+	w, err := c.create(h)
+	if err != nil {
+		return rc
+	}
+	return &teeReadCloser{rc: rc, w: w}
+}
+
+type teeReadCloser struct {
+	rc io.ReadCloser
+	w  *blobWriter
+}
+
+func (t *teeReadCloser) Read(p []byte) (int, error) {
+	n, err := t.rc.Read(p)
+	if t.w != nil && n > 0 {
+		if _, werr := t.w.Write(p[:n]); werr != nil {
+			t.w.abort()
+			t.w = nil
+		}
+	}
+	if t.w != nil && errors.Is(err, io.EOF) {
+		_ = t.w.commit()
+		t.w = nil
+	}
+	return n, err
+}
+
+func (t *teeReadCloser) Close() error {
+	if t.w != nil {
+		t.w.abort()
+		t.w = nil
+	}
+	return t.rc.Close()
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+)
+
+// maxManifestSize bounds the manifests the transport will buffer and store.
+const maxManifestSize = 4 << 20
+
+// Transport returns a RoundTripper that answers GET requests for manifests
+// by digest from the cache, storing those it has to fetch. Manifests by tag
+// and every other request pass through to inner.
+func (c *Cache) Transport(inner http.RoundTripper) http.RoundTripper {
+	return &transport{inner: inner, c: c}
+}
+
+type transport struct {
+	inner http.RoundTripper
+	c     *Cache
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	h, ok := manifestDigest(req)
+	if !ok {
+		return t.inner.RoundTrip(req)
+	}
+	if b, err := t.c.Get(h); err == nil {
+		return manifestResponse(req, h, b), nil
+	}
+
+	resp, err := t.inner.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, maxManifestSize+1))
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(b))
+
+	// The media type is not stored, so only keep manifests whose type can be
+	// recovered from their contents.
+	mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if len(b) <= maxManifestSize && mt != "" && mt == string(sniffMediaType(b)) {
+		_ = t.c.Put(h, b)
+	}
+	return resp, nil
+}
+
+// manifestDigest reports the digest a GET request for
+// /v2/<name>/manifests/<digest> asks for.
+func manifestDigest(req *http.Request) (v1.Hash, bool) {
+	if req.Method != http.MethodGet || !strings.HasPrefix(req.URL.Path, "/v2/") {
+		return v1.Hash{}, false
+	}
+	i := strings.LastIndex(req.URL.Path, "/manifests/")
+	if i < 0 {
+		return v1.Hash{}, false
+	}
+	h, err := v1.NewHash(req.URL.Path[i+len("/manifests/"):])
+	if err != nil {
+		return v1.Hash{}, false
+	}
+	return h, true
+}
+
+func manifestResponse(req *http.Request, h v1.Hash, b []byte) *http.Response {
+	header := http.Header{}
+	header.Set("Content-Type", string(sniffMediaType(b)))
+	header.Set("Content-Length", strconv.Itoa(len(b)))
+	header.Set("Docker-Content-Digest", h.String())
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(b)),
+		ContentLength: int64(len(b)),
+		Request:       req,
+	}
+}
+
+// sniffMediaType recovers a manifest's media type from its contents: the
+// mediaType field when set, otherwise an OCI index or manifest depending on
+// whether it lists manifests.
+func sniffMediaType(b []byte) types.MediaType {
+	var m struct {
+		MediaType types.MediaType `json:"mediaType"`
+		Manifests json.RawMessage `json:"manifests"`
+	}
+	if err := json.Unmarshal(b, &m); err != nil {
+		return ""
+	}
+	switch {
+	case m.MediaType != "":
+		return m.MediaType
+	case m.Manifests != nil:
+		return types.OCIImageIndex
+	default:
+		return types.OCIManifestSchema1
+	}
+}
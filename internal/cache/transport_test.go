@@ -0,0 +1,55 @@
+package cache_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/cache"
+)
+
+func TestTransport_ServesManifestsByDigestFromCache(t *testing.T) {
+	manifest := []byte(`{"schemaVersion":2,"mediaType":"application/vnd.oci.image.manifest.v1+json"}`)
+	h := digestOf(manifest)
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		_, _ = w.Write(manifest)
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: cache.New(t.TempDir(), 1<<20).Transport(http.DefaultTransport)}
+	get := func(path string) string {
+		t.Helper()
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = resp.Body.Close() }()
+		b, err := io.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ct := resp.Header.Get("Content-Type"); ct != "application/vnd.oci.image.manifest.v1+json" {
+			t.Errorf("got Content-Type %q", ct)
+		}
+		return string(b)
+	}
+
+	for range 2 {
+		if got := get("/v2/app/manifests/" + h.String()); got != string(manifest) {
+			t.Errorf("got body %q", got)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests for a manifest by digest, want 1", requests)
+	}
+
+	get("/v2/app/manifests/latest")
+	get("/v2/app/manifests/latest")
+	if requests != 3 {
+		t.Errorf("got %d requests in total, want tag lookups to reach the registry", requests)
+	}
+}
@@ -0,0 +1,79 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// AnalyzeData holds the space usage of an image for output.
+type AnalyzeData struct {
+	Reference         string           `json:"reference"`
+	Digest            string           `json:"digest"`
+	Layers            []LayerSizeData  `json:"layers"`
+	CompressedBytes   int64            `json:"compressed_bytes"`
+	UncompressedBytes int64            `json:"uncompressed_bytes"`
+	WastedBytes       int64            `json:"wasted_bytes"`
+	Efficiency        float64          `json:"efficiency"` // percentage, 0-100
+	Wasted            []WastedPathData `json:"wasted"`
+}
+
+// LayerSizeData holds the compressed and uncompressed size of one layer.
+type LayerSizeData struct {
+	Index            int    `json:"index"`
+	Digest           string `json:"digest"`
+	CompressedSize   int64  `json:"compressed_size"`
+	UncompressedSize int64  `json:"uncompressed_size"`
+	Files            int    `json:"files"`
+}
+
+// WastedPathData is a path whose hidden versions waste space in the image.
+type WastedPathData struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+	Count int    `json:"count"`
+}
+
+// PrintAnalyze writes an image's space usage to w in the requested format.
+func PrintAnalyze(w io.Writer, data AnalyzeData, f Format) error {
+	return render(w, data, f, func() error { return printAnalyzeHuman(w, data) })
+}
+
+func printAnalyzeHuman(w io.Writer, data AnalyzeData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Reference:\t%s\n", data.Reference)
+	_, _ = fmt.Fprintf(tw, "Digest:\t%s\n", data.Digest)
+	_, _ = fmt.Fprintf(tw, "Compressed:\t%s\n", HumanSize(data.CompressedBytes))
+	_, _ = fmt.Fprintf(tw, "Uncompressed:\t%s\n", HumanSize(data.UncompressedBytes))
+	_, _ = fmt.Fprintf(tw, "Wasted:\t%s\n", HumanSize(data.WastedBytes))
+	_, _ = fmt.Fprintf(tw, "Efficiency:\t%.2f%%\n", data.Efficiency)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "#\tDIGEST\tCOMPRESSED\tUNCOMPRESSED\tFILES\n")
+	for _, l := range data.Layers {
+		digest := l.Digest
+		if len(digest) > 19 {
+			digest = digest[:19]
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%d\n",
+			l.Index+1, digest, HumanSize(l.CompressedSize), HumanSize(l.UncompressedSize), l.Files)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if len(data.Wasted) == 0 {
+		return nil
+	}
+	_, _ = fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "WASTED\tCOUNT\tPATH\n")
+	for _, p := range data.Wasted {
+		_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\n", HumanSize(p.Bytes), p.Count, p.Path)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,26 @@
+package format
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// BatchError reports one image that failed in a batch, so a JSON Lines
+// stream has a line for every input.
+type BatchError struct {
+	Reference string `json:"reference"`
+	Error     string `json:"error"`
+	Kind      string `json:"kind"`
+}
+
+// ImageLayersData is one image's layers, as a single JSON Lines record.
+type ImageLayersData struct {
+	Reference string      `json:"reference"`
+	Layers    []LayerData `json:"layers"`
+}
+
+// PrintJSONLine writes v to w as compact JSON followed by a newline, one
+// record of a JSON Lines stream.
+func PrintJSONLine(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,45 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// CacheInfoData describes the on-disk cache for output.
+type CacheInfoData struct {
+	Dir          string `json:"dir"`
+	Entries      int    `json:"entries"`
+	SizeBytes    int64  `json:"size_bytes"`
+	MaxSizeBytes int64  `json:"max_size_bytes"`
+}
+
+// CachePruneData reports what a cache prune removed and what remains.
+type CachePruneData struct {
+	Dir          string `json:"dir"`
+	Removed      int    `json:"removed"`
+	FreedBytes   int64  `json:"freed_bytes"`
+	Entries      int    `json:"entries"`
+	SizeBytes    int64  `json:"size_bytes"`
+	MaxSizeBytes int64  `json:"max_size_bytes"`
+}
+
+// PrintCacheInfo writes the cache's location and usage to w in the requested format.
+func PrintCacheInfo(w io.Writer, data CacheInfoData, f Format) error {
+	return render(w, data, f, func() error {
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "Directory:\t%s\n", data.Dir)
+		_, _ = fmt.Fprintf(tw, "Entries:\t%d\n", data.Entries)
+		_, _ = fmt.Fprintf(tw, "Size:\t%s of %s\n", HumanSize(data.SizeBytes), HumanSize(data.MaxSizeBytes))
+		return tw.Flush()
+	})
+}
+
+// PrintCachePrune writes the result of a cache prune to w in the requested format.
+func PrintCachePrune(w io.Writer, data CachePruneData, f Format) error {
+	return render(w, data, f, func() error {
+		_, _ = fmt.Fprintf(w, "Removed %d entries, freeing %s; %s in %d entries remain in %s\n",
+			data.Removed, HumanSize(data.FreedBytes), HumanSize(data.SizeBytes), data.Entries, data.Dir)
+		return nil
+	})
+}
@@ -0,0 +1,102 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// DiffData holds the comparison of two images for output.
+type DiffData struct {
+	ImageA  string         `json:"image_a"`
+	DigestA string         `json:"digest_a"`
+	ImageB  string         `json:"image_b"`
+	DigestB string         `json:"digest_b"`
+	Config  []ConfigChange `json:"config"`
+	Layers  LayerDiff      `json:"layers"`
+	// Files is nil unless the filesystems were compared, and empty when
+	// they were and nothing differs.
+	Files []FileChange `json:"files"`
+}
+
+// ConfigChange is a config field whose value differs between the two images.
+// A nil A or B means the field is unset in that image, as opposed to set to
+// an empty value.
+type ConfigChange struct {
+	Field string  `json:"field"`
+	A     *string `json:"a"`
+	B     *string `json:"b"`
+}
+
+// LayerDiff splits two layer stacks into their common base and divergent tails.
+type LayerDiff struct {
+	Shared int      `json:"shared"`
+	OnlyA  []string `json:"only_a"`
+	OnlyB  []string `json:"only_b"`
+}
+
+// PrintDiff writes an image comparison to w in the requested format.
+func PrintDiff(w io.Writer, data DiffData, f Format) error {
+	return render(w, data, f, func() error { return printDiffHuman(w, data) })
+}
+
+func printDiffHuman(w io.Writer, data DiffData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Image A:\t%s\t%s\n", data.ImageA, data.DigestA)
+	_, _ = fmt.Fprintf(tw, "Image B:\t%s\t%s\n", data.ImageB, data.DigestB)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(w, "\nConfig:\n")
+	if len(data.Config) == 0 {
+		_, _ = fmt.Fprintf(w, "  no differences\n")
+	} else {
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "  FIELD\tA\tB\n")
+		for _, c := range data.Config {
+			_, _ = fmt.Fprintf(tw, "  %s\t%s\t%s\n", c.Field, settingOrNone(c.A), settingOrNone(c.B))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	_, _ = fmt.Fprintf(w, "\nLayers: %d shared, %d only in A, %d only in B\n",
+		data.Layers.Shared, len(data.Layers.OnlyA), len(data.Layers.OnlyB))
+	for _, d := range data.Layers.OnlyA {
+		_, _ = fmt.Fprintf(w, "  - %s\n", d)
+	}
+	for _, d := range data.Layers.OnlyB {
+		_, _ = fmt.Fprintf(w, "  + %s\n", d)
+	}
+
+	if data.Files != nil {
+		_, _ = fmt.Fprintf(w, "\nFiles:\n")
+		if len(data.Files) == 0 {
+			_, _ = fmt.Fprintf(w, "  no differences\n")
+		}
+		for _, f := range data.Files {
+			_, _ = fmt.Fprintf(w, "  %s %s\n", changeMarker(f.Kind), f.Path)
+		}
+	}
+	return nil
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
+
+// settingOrNone shows an unset value as <none> and an empty one as "".
+func settingOrNone(s *string) string {
+	switch {
+	case s == nil:
+		return "<none>"
+	case *s == "":
+		return `""`
+	}
+	return *s
+}
@@ -0,0 +1,53 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// DigestData describes what a reference resolves to, for output.
+type DigestData struct {
+	Reference   string   `json:"reference"`
+	Source      string   `json:"source"` // daemon, registry, oci-layout or archive
+	Digest      string   `json:"digest,omitempty"`
+	MediaType   string   `json:"media_type,omitempty"`
+	Size        int64    `json:"size,omitempty"`
+	ImageID     string   `json:"image_id,omitempty"`
+	RepoDigests []string `json:"repo_digests,omitempty"`
+	Pinned      string   `json:"pinned,omitempty"` // repo:tag@digest, with --pin
+}
+
+// PrintDigest writes a resolved digest to w in the requested format. When the
+// reference was pinned, human output is just the pinned reference so it can
+// be substituted straight into a manifest.
+func PrintDigest(w io.Writer, data DigestData, f Format) error {
+	return render(w, data, f, func() error {
+		if data.Pinned != "" {
+			_, err := fmt.Fprintln(w, data.Pinned)
+			return err
+		}
+		return printDigestHuman(w, data)
+	})
+}
+
+func printDigestHuman(w io.Writer, data DigestData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Reference:\t%s\n", data.Reference)
+	_, _ = fmt.Fprintf(tw, "Source:\t%s\n", data.Source)
+	if data.Digest != "" {
+		_, _ = fmt.Fprintf(tw, "Digest:\t%s\n", data.Digest)
+		_, _ = fmt.Fprintf(tw, "Media Type:\t%s\n", data.MediaType)
+		_, _ = fmt.Fprintf(tw, "Size:\t%s\n", HumanSize(data.Size))
+	}
+	if data.ImageID != "" {
+		_, _ = fmt.Fprintf(tw, "Image ID:\t%s\n", data.ImageID)
+	}
+	if len(data.RepoDigests) > 0 {
+		_, _ = fmt.Fprintf(tw, "Repo Digests:\n")
+		for _, d := range data.RepoDigests {
+			_, _ = fmt.Fprintf(tw, "  %s\t\n", d)
+		}
+	}
+	return tw.Flush()
+}
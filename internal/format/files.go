@@ -0,0 +1,41 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// FileData describes one entry of an image's merged filesystem.
+type FileData struct {
+	Path     string `json:"path"`
+	Type     string `json:"type"` // file, dir, symlink, hardlink, char, block, fifo
+	Mode     string `json:"mode"` // ls-style, e.g. "-rw-r--r--"
+	UID      int    `json:"uid"`
+	GID      int    `json:"gid"`
+	Owner    string `json:"owner"`
+	Size     int64  `json:"size"`
+	ModTime  string `json:"mtime,omitempty"`
+	LinkName string `json:"link_target,omitempty"`
+	Layer    int    `json:"layer"` // -1 for directories implied by their contents
+}
+
+// PrintFiles writes a filesystem listing to w in the requested format.
+func PrintFiles(w io.Writer, data []FileData, f Format) error {
+	return render(w, data, f, func() error { return printFilesHuman(w, data) })
+}
+
+func printFilesHuman(w io.Writer, data []FileData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, e := range data {
+		name := e.Path
+		switch e.Type {
+		case "symlink":
+			name += " -> " + e.LinkName
+		case "hardlink":
+			name += " link to " + e.LinkName
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\n", e.Mode, e.Owner, e.Size, orNone(e.ModTime), name)
+	}
+	return tw.Flush()
+}
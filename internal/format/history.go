@@ -0,0 +1,41 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// HistoryData is one build step from an image's history.
+type HistoryData struct {
+	Index      int    `json:"index"`
+	Created    string `json:"created,omitempty"` // RFC 3339
+	CreatedBy  string `json:"created_by"`
+	Author     string `json:"author,omitempty"`
+	Comment    string `json:"comment,omitempty"`
+	EmptyLayer bool   `json:"empty_layer"`
+	Layer      string `json:"layer,omitempty"` // digest of the layer the step produced
+}
+
+// PrintHistory writes an image's build history to w in the requested format.
+func PrintHistory(w io.Writer, data []HistoryData, f Format) error {
+	return render(w, data, f, func() error { return printHistoryHuman(w, data) })
+}
+
+func printHistoryHuman(w io.Writer, data []HistoryData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "#\tCREATED\tLAYER\tAUTHOR\tCOMMAND\tCOMMENT\n")
+	for _, h := range data {
+		digest := h.Layer
+		if len(digest) > 19 {
+			digest = digest[:19]
+		}
+		command := h.CreatedBy
+		if len(command) > 80 {
+			command = command[:77] + "..."
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\n",
+			h.Index+1, orNone(h.Created), orNone(digest), h.Author, command, h.Comment)
+	}
+	return tw.Flush()
+}
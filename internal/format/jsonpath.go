@@ -0,0 +1,203 @@
+package format
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a compiled JSONPath template in the kubectl style: literal text
+// with {expressions} such as {.labels.version}, {[0].digest} or {[*].size}.
+// Expressions are evaluated against the JSON form of the output data, so
+// field names are the JSON names.
+type jsonPath struct {
+	segments []pathSegment
+}
+
+// pathSegment is either literal text or an expression made of steps.
+type pathSegment struct {
+	text  string
+	steps []pathStep
+	expr  bool
+}
+
+// pathStep selects a key, an index, or (wildcard) every child.
+type pathStep struct {
+	key      string
+	index    int
+	isIndex  bool
+	wildcard bool
+}
+
+func parseJSONPath(tmpl string) (*jsonPath, error) {
+	if tmpl == "" {
+		return nil, errors.New("jsonpath: empty expression")
+	}
+	p := &jsonPath{}
+	for rest := tmpl; rest != ""; {
+		open := strings.IndexByte(rest, '{')
+		if open < 0 {
+			p.segments = append(p.segments, pathSegment{text: rest})
+			break
+		}
+		if open > 0 {
+			p.segments = append(p.segments, pathSegment{text: rest[:open]})
+		}
+		end := strings.IndexByte(rest[open:], '}')
+		if end < 0 {
+			return nil, fmt.Errorf("jsonpath: unclosed { in %q", tmpl)
+		}
+		steps, err := parseSteps(rest[open+1 : open+end])
+		if err != nil {
+			return nil, err
+		}
+		p.segments = append(p.segments, pathSegment{steps: steps, expr: true})
+		rest = rest[open+end+1:]
+	}
+	return p, nil
+}
+
+func parseSteps(expr string) ([]pathStep, error) {
+	expr = strings.TrimSpace(expr)
+	expr = strings.TrimPrefix(expr, "$")
+	var steps []pathStep
+	for expr != "" {
+		switch expr[0] {
+		case '.':
+			expr = expr[1:]
+			if expr == "" {
+				return steps, nil // "{.}" is the whole document
+			}
+			n := strings.IndexAny(expr, ".[")
+			if n < 0 {
+				n = len(expr)
+			}
+			key := expr[:n]
+			if key == "" {
+				return nil, errors.New("jsonpath: empty field name")
+			}
+			if key == "*" {
+				steps = append(steps, pathStep{wildcard: true})
+			} else {
+				steps = append(steps, pathStep{key: key})
+			}
+			expr = expr[n:]
+		case '[':
+			end := strings.IndexByte(expr, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath: unclosed [ in %q", expr)
+			}
+			inner := strings.TrimSpace(expr[1:end])
+			switch {
+			case inner == "*":
+				steps = append(steps, pathStep{wildcard: true})
+			case len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0]:
+				steps = append(steps, pathStep{key: inner[1 : len(inner)-1]})
+			default:
+				i, err := strconv.Atoi(inner)
+				if err != nil {
+					return nil, fmt.Errorf("jsonpath: invalid index %q", inner)
+				}
+				steps = append(steps, pathStep{index: i, isIndex: true})
+			}
+			expr = expr[end+1:]
+		default:
+			return nil, fmt.Errorf("jsonpath: unexpected %q, expressions start with . or [", expr)
+		}
+	}
+	return steps, nil
+}
+
+// execute evaluates the template against v. Missing keys and out-of-range
+// indexes produce no output rather than an error, so scripts can probe for
+// optional fields such as labels.
+func (p *jsonPath) execute(w io.Writer, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var doc any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return err
+	}
+
+	var sb strings.Builder
+	for _, seg := range p.segments {
+		if !seg.expr {
+			sb.WriteString(seg.text)
+			continue
+		}
+		results := []any{doc}
+		for _, step := range seg.steps {
+			results = applyStep(results, step)
+		}
+		for i, r := range results {
+			if i > 0 {
+				sb.WriteByte(' ')
+			}
+			s, err := jsonPathString(r)
+			if err != nil {
+				return err
+			}
+			sb.WriteString(s)
+		}
+	}
+	sb.WriteByte('\n')
+	_, err = io.WriteString(w, sb.String())
+	return err
+}
+
+func applyStep(in []any, step pathStep) []any {
+	var out []any
+	for _, v := range in {
+		switch node := v.(type) {
+		case map[string]any:
+			if step.wildcard {
+				for _, k := range sortedKeys(node) {
+					out = append(out, node[k])
+				}
+			} else if child, ok := node[step.key]; ok && !step.isIndex {
+				out = append(out, child)
+			}
+		case []any:
+			switch {
+			case step.wildcard:
+				out = append(out, node...)
+			case step.isIndex:
+				i := step.index
+				if i < 0 {
+					i += len(node)
+				}
+				if i >= 0 && i < len(node) {
+					out = append(out, node[i])
+				}
+			}
+		}
+	}
+	return out
+}
+
+// jsonPathString renders a result: strings bare, everything else as JSON.
+func jsonPathString(v any) (string, error) {
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case nil:
+		return "", nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,101 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// ImageManifestData holds an image manifest and its descriptors for output.
+type ImageManifestData struct {
+	Reference     string            `json:"reference"`
+	Digest        string            `json:"digest"`
+	MediaType     string            `json:"media_type"`
+	SchemaVersion int64             `json:"schema_version"`
+	Config        DescriptorData    `json:"config"`
+	Layers        []DescriptorData  `json:"layers"`
+	Subject       *DescriptorData   `json:"subject,omitempty"`
+	Annotations   map[string]string `json:"annotations,omitempty"`
+}
+
+// DescriptorData describes a blob referenced by a manifest.
+type DescriptorData struct {
+	MediaType        string            `json:"media_type"`
+	Digest           string            `json:"digest"`
+	Size             int64             `json:"size"`
+	Compression      string            `json:"compression,omitempty"` // layers only: gzip, zstd, estargz or none
+	NonDistributable bool              `json:"non_distributable,omitempty"`
+	URLs             []string          `json:"urls,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
+}
+
+// PrintManifest writes an image manifest to w in the requested format.
+func PrintManifest(w io.Writer, data ImageManifestData, f Format) error {
+	return render(w, data, f, func() error { return printManifestHuman(w, data) })
+}
+
+func printManifestHuman(w io.Writer, data ImageManifestData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Reference:\t%s\n", data.Reference)
+	_, _ = fmt.Fprintf(tw, "Digest:\t%s\n", data.Digest)
+	_, _ = fmt.Fprintf(tw, "Media Type:\t%s\n", data.MediaType)
+	_, _ = fmt.Fprintf(tw, "Schema Version:\t%d\n", data.SchemaVersion)
+	_, _ = fmt.Fprintf(tw, "Config:\t%s\t%s\t%s\n", data.Config.MediaType, data.Config.Digest, HumanSize(data.Config.Size))
+	if data.Subject != nil {
+		_, _ = fmt.Fprintf(tw, "Subject:\t%s\t%s\n", data.Subject.MediaType, data.Subject.Digest)
+	}
+	printAnnotations(tw, "", data.Annotations)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "#\tDIGEST\tSIZE\tCOMPRESSION\tMEDIA TYPE\n")
+	for i, l := range data.Layers {
+		digest := l.Digest
+		if len(digest) > 19 {
+			digest = digest[:19]
+		}
+		compression := l.Compression
+		if l.NonDistributable {
+			compression += " (non-distributable)"
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", i+1, digest, HumanSize(l.Size), compression, l.MediaType)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for i, l := range data.Layers {
+		if len(l.URLs) == 0 && len(l.Annotations) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "\nLayer %d:\n", i+1)
+		for _, u := range l.URLs {
+			_, _ = fmt.Fprintf(w, "  url: %s\n", u)
+		}
+		printAnnotations(w, "  ", l.Annotations)
+	}
+	return nil
+}
+
+// printAnnotations writes annotations sorted by key, each on its own line
+// starting with indent.
+func printAnnotations(w io.Writer, indent string, annotations map[string]string) {
+	if len(annotations) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(annotations))
+	for k := range annotations {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	if indent == "" {
+		_, _ = fmt.Fprintf(w, "Annotations:\n")
+		indent = "  "
+	}
+	for _, k := range keys {
+		_, _ = fmt.Fprintf(w, "%s%s=%s\n", indent, k, annotations[k])
+	}
+}
@@ -1,74 +1,191 @@
 package format
 
 import (
-	"encoding/json"
 	"fmt"
 	"io"
+	"math"
+	"strconv"
+	"strings"
 	"text/tabwriter"
 )
 
-// Format controls output rendering.
+// Format controls output rendering. It is the name of a registered Renderer
+// (or human), optionally followed by "=" and an argument, as in
+// "template={{.Digest}}" or "jsonpath={.digest}".
 type Format string
 
 const (
 	Human Format = "human"
 	JSON  Format = "json"
+	YAML  Format = "yaml"
+	CSV   Format = "csv"
 )
 
+// Parse validates s as an output format, checking any argument (such as a
+// template) so mistakes are reported before an image is loaded.
+func Parse(s string) (Format, error) {
+	name, arg, hasArg := strings.Cut(s, "=")
+	if name == string(Human) && !hasArg {
+		return Human, nil
+	}
+	r, ok := renderers[name]
+	if !ok {
+		return "", fmt.Errorf("unknown output format %q: want one of %s", s, strings.Join(Names(), ", "))
+	}
+	if r.ParseArg == nil {
+		if hasArg {
+			return "", fmt.Errorf("output format %q takes no argument", name)
+		}
+		return Format(s), nil
+	}
+	if err := r.ParseArg(arg); err != nil {
+		return "", err
+	}
+	return Format(s), nil
+}
+
 // InspectData holds image configuration metadata for output.
 type InspectData struct {
-	Reference  string            `json:"reference"`
-	Digest     string            `json:"digest"`
-	OS         string            `json:"os"`
-	Arch       string            `json:"arch"`
-	Created    string            `json:"created"`
-	SizeBytes  int64             `json:"size_bytes"`
-	Entrypoint []string          `json:"entrypoint"`
-	Cmd        []string          `json:"cmd"`
-	Env        []string          `json:"env"`
-	Ports      []string          `json:"ports"`
-	Labels     map[string]string `json:"labels"`
+	Reference     string            `json:"reference"`
+	Digest        string            `json:"digest"`
+	Index         string            `json:"index,omitempty"`
+	OS            string            `json:"os"`
+	Arch          string            `json:"arch"`
+	Variant       string            `json:"variant,omitempty"`
+	OSVersion     string            `json:"os_version,omitempty"`
+	OSFeatures    []string          `json:"os_features,omitempty"`
+	Created       string            `json:"created"`
+	Author        string            `json:"author,omitempty"`
+	DockerVersion string            `json:"docker_version,omitempty"`
+	SizeBytes     int64             `json:"size_bytes"`
+	User          string            `json:"user,omitempty"`
+	WorkingDir    string            `json:"working_dir,omitempty"`
+	Entrypoint    []string          `json:"entrypoint"`
+	Cmd           []string          `json:"cmd"`
+	Shell         []string          `json:"shell,omitempty"`
+	ArgsEscaped   bool              `json:"args_escaped,omitempty"`
+	Env           []string          `json:"env"`
+	Ports         []string          `json:"ports"`
+	Volumes       []string          `json:"volumes,omitempty"`
+	Labels        map[string]string `json:"labels"`
+	StopSignal    string            `json:"stop_signal,omitempty"`
+	Healthcheck   *HealthcheckData  `json:"healthcheck,omitempty"`
+	OnBuild       []string          `json:"on_build,omitempty"`
+}
+
+// HealthcheckData is an image's HEALTHCHECK. Durations are Go duration
+// strings such as "30s"; empty means the runtime default.
+type HealthcheckData struct {
+	Test        []string `json:"test"`
+	Interval    string   `json:"interval,omitempty"`
+	Timeout     string   `json:"timeout,omitempty"`
+	StartPeriod string   `json:"start_period,omitempty"`
+	Retries     int      `json:"retries,omitempty"`
 }
 
 // LayerData holds per-layer information for output.
 type LayerData struct {
-	Index   int    `json:"index"`
-	Digest  string `json:"digest"`
-	Size    int64  `json:"size"`
-	Command string `json:"command"`
+	Index       int    `json:"index"`
+	Digest      string `json:"digest"`
+	Size        int64  `json:"size"`
+	MediaType   string `json:"media_type"`
+	Compression string `json:"compression"` // gzip, zstd, estargz or none
+	Command     string `json:"command"`
+	// Files is nil unless --files was given, and empty for a layer that
+	// changes nothing.
+	Files []FileChange `json:"files,omitzero"`
+}
+
+// FileChange is a path added, modified or deleted by a layer.
+type FileChange struct {
+	Path string `json:"path"`
+	Kind string `json:"kind"` // "added", "modified" or "deleted"
+}
+
+// IndexData holds a multi-platform index and its child manifests for output.
+type IndexData struct {
+	Reference string         `json:"reference"`
+	Digest    string         `json:"digest"`
+	MediaType string         `json:"media_type"`
+	Manifests []ManifestData `json:"manifests"`
+}
+
+// ManifestData describes one child manifest of an index.
+type ManifestData struct {
+	Platform  string `json:"platform"`
+	Digest    string `json:"digest"`
+	MediaType string `json:"media_type"`
+	Size      int64  `json:"size"`
+}
+
+// ErrorData describes a failed command for JSON output.
+type ErrorData struct {
+	Error    string `json:"error"`
+	Kind     string `json:"kind"`
+	ExitCode int    `json:"exit_code"`
 }
 
 // PrintInspect writes image metadata to w in the requested format.
 func PrintInspect(w io.Writer, data InspectData, f Format) error {
-	if f == JSON {
-		return printJSON(w, data)
-	}
-	return printInspectHuman(w, data)
+	return render(w, data, f, func() error { return printInspectHuman(w, data) })
 }
 
 // PrintLayers writes layer data to w in the requested format.
 func PrintLayers(w io.Writer, layers []LayerData, f Format) error {
-	if f == JSON {
-		return printJSON(w, layers)
-	}
-	return printLayersHuman(w, layers)
+	return render(w, layers, f, func() error { return printLayersHuman(w, layers) })
+}
+
+// PrintIndex writes an index's child manifests to w in the requested format.
+func PrintIndex(w io.Writer, data IndexData, f Format) error {
+	return render(w, data, f, func() error { return printIndexHuman(w, data) })
 }
 
-func printJSON(w io.Writer, v any) error {
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	return enc.Encode(v)
+// PrintError writes a command failure to w. Human output is the bare message,
+// matching what cobra would print; JSON output wraps it in ErrorData.
+func PrintError(w io.Writer, data ErrorData, f Format) error {
+	if f == JSON {
+		return printJSON(w, data)
+	}
+	_, err := fmt.Fprintln(w, "Error:", data.Error)
+	return err
 }
 
 func printInspectHuman(w io.Writer, data InspectData) error {
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
 	_, _ = fmt.Fprintf(tw, "Reference:\t%s\n", data.Reference)
 	_, _ = fmt.Fprintf(tw, "Digest:\t%s\n", data.Digest)
-	_, _ = fmt.Fprintf(tw, "OS/Arch:\t%s/%s\n", data.OS, data.Arch)
+	if data.Index != "" {
+		_, _ = fmt.Fprintf(tw, "Index:\t%s (multi-platform)\n", data.Index)
+	}
+	if data.Variant != "" {
+		_, _ = fmt.Fprintf(tw, "OS/Arch:\t%s/%s/%s\n", data.OS, data.Arch, data.Variant)
+	} else {
+		_, _ = fmt.Fprintf(tw, "OS/Arch:\t%s/%s\n", data.OS, data.Arch)
+	}
+	if data.OSVersion != "" {
+		_, _ = fmt.Fprintf(tw, "OS Version:\t%s\n", data.OSVersion)
+	}
+	if len(data.OSFeatures) > 0 {
+		_, _ = fmt.Fprintf(tw, "OS Features:\t%v\n", data.OSFeatures)
+	}
 	_, _ = fmt.Fprintf(tw, "Created:\t%s\n", data.Created)
+	if data.Author != "" {
+		_, _ = fmt.Fprintf(tw, "Author:\t%s\n", data.Author)
+	}
+	if data.DockerVersion != "" {
+		_, _ = fmt.Fprintf(tw, "Docker Version:\t%s\n", data.DockerVersion)
+	}
 	_, _ = fmt.Fprintf(tw, "Size:\t%s\n", HumanSize(data.SizeBytes))
+	_, _ = fmt.Fprintf(tw, "User:\t%s\n", orNone(data.User))
+	_, _ = fmt.Fprintf(tw, "WorkingDir:\t%s\n", orNone(data.WorkingDir))
 	_, _ = fmt.Fprintf(tw, "Entrypoint:\t%v\n", data.Entrypoint)
 	_, _ = fmt.Fprintf(tw, "Cmd:\t%v\n", data.Cmd)
+	if len(data.Shell) > 0 {
+		_, _ = fmt.Fprintf(tw, "Shell:\t%v\n", data.Shell)
+	}
+	if data.ArgsEscaped {
+		_, _ = fmt.Fprintf(tw, "ArgsEscaped:\ttrue\n")
+	}
 	if len(data.Env) > 0 {
 		_, _ = fmt.Fprintf(tw, "Env:\n")
 		for _, e := range data.Env {
@@ -78,24 +195,98 @@ func printInspectHuman(w io.Writer, data InspectData) error {
 	if len(data.Ports) > 0 {
 		_, _ = fmt.Fprintf(tw, "Ports:\t%v\n", data.Ports)
 	}
+	if len(data.Volumes) > 0 {
+		_, _ = fmt.Fprintf(tw, "Volumes:\t%v\n", data.Volumes)
+	}
 	if len(data.Labels) > 0 {
 		_, _ = fmt.Fprintf(tw, "Labels:\n")
 		for k, v := range data.Labels {
 			_, _ = fmt.Fprintf(tw, "  %s=%s\t\n", k, v)
 		}
 	}
+	if data.StopSignal != "" {
+		_, _ = fmt.Fprintf(tw, "StopSignal:\t%s\n", data.StopSignal)
+	}
+	if h := data.Healthcheck; h != nil {
+		_, _ = fmt.Fprintf(tw, "Healthcheck:\t%v\n", h.Test)
+		for _, opt := range []struct{ name, value string }{
+			{"interval", h.Interval},
+			{"timeout", h.Timeout},
+			{"start period", h.StartPeriod},
+		} {
+			if opt.value != "" {
+				_, _ = fmt.Fprintf(tw, "  %s\t%s\n", opt.name, opt.value)
+			}
+		}
+		if h.Retries > 0 {
+			_, _ = fmt.Fprintf(tw, "  retries\t%d\n", h.Retries)
+		}
+	}
+	if len(data.OnBuild) > 0 {
+		_, _ = fmt.Fprintf(tw, "OnBuild:\n")
+		for _, step := range data.OnBuild {
+			_, _ = fmt.Fprintf(tw, "  %s\t\n", step)
+		}
+	}
 	return tw.Flush()
 }
 
 func printLayersHuman(w io.Writer, layers []LayerData) error {
 	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
-	_, _ = fmt.Fprintf(tw, "#\tDIGEST\tSIZE\tCOMMAND\n")
+	_, _ = fmt.Fprintf(tw, "#\tDIGEST\tSIZE\tCOMPRESSION\tCOMMAND\n")
 	for _, l := range layers {
 		digest := l.Digest
 		if len(digest) > 19 {
 			digest = digest[:19]
 		}
-		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\n", l.Index+1, digest, HumanSize(l.Size), l.Command)
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", l.Index+1, digest, HumanSize(l.Size), orNone(l.Compression), l.Command)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	for _, l := range layers {
+		if len(l.Files) == 0 {
+			continue
+		}
+		_, _ = fmt.Fprintf(w, "\nLayer %d files:\n", l.Index+1)
+		for _, f := range l.Files {
+			_, _ = fmt.Fprintf(w, "  %s %s\n", changeMarker(f.Kind), f.Path)
+		}
+	}
+	return nil
+}
+
+// changeMarker returns the single-letter marker used for a change kind in
+// human output, in the style of `docker diff`.
+func changeMarker(kind string) string {
+	switch kind {
+	case "added":
+		return "A"
+	case "deleted":
+		return "D"
+	default:
+		return "M"
+	}
+}
+
+func printIndexHuman(w io.Writer, data IndexData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "Reference:\t%s\n", data.Reference)
+	_, _ = fmt.Fprintf(tw, "Digest:\t%s\n", data.Digest)
+	_, _ = fmt.Fprintf(tw, "Media Type:\t%s\n", data.MediaType)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "PLATFORM\tDIGEST\tSIZE\n")
+	for _, m := range data.Manifests {
+		platform := m.Platform
+		if platform == "" {
+			platform = "<unknown>"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", platform, m.Digest, HumanSize(m.Size))
 	}
 	return tw.Flush()
 }
@@ -113,3 +304,26 @@ func HumanSize(bytes int64) string {
 	}
 	return fmt.Sprintf("%.2f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
+
+// ParseSize parses a byte count such as "512", "100MB" or "1.5GiB", the
+// inverse of HumanSize. Units are powers of 1024 and case-insensitive.
+func ParseSize(s string) (int64, error) {
+	num := strings.TrimSpace(s)
+	unit := strings.TrimLeft(num, "0123456789.")
+	num = strings.TrimSpace(strings.TrimSuffix(num, unit))
+	unit = strings.ToUpper(strings.TrimSpace(unit))
+	unit = strings.TrimSuffix(strings.TrimSuffix(unit, "B"), "I")
+
+	exp := 0
+	if unit != "" {
+		exp = strings.Index("KMGTPE", unit) + 1
+		if len(unit) != 1 || exp == 0 {
+			return 0, fmt.Errorf("unknown unit in size %q", s)
+		}
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+	return int64(n * math.Pow(1024, float64(exp))), nil
+}
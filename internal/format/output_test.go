@@ -52,6 +52,32 @@ func TestPrintInspect_Human(t *testing.T) {
 	}
 }
 
+func TestPrintInspect_HumanFullConfig(t *testing.T) {
+	data := format.InspectData{
+		Reference:  "app:latest",
+		OS:         "linux",
+		Arch:       "amd64",
+		User:       "app",
+		WorkingDir: "/srv",
+		Volumes:    []string{"/data"},
+		StopSignal: "SIGQUIT",
+		Healthcheck: &format.HealthcheckData{
+			Test:     []string{"CMD", "true"},
+			Interval: "30s",
+			Retries:  3,
+		},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintInspect(&buf, data, format.Human); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"User:", "app", "WorkingDir:", "/srv", "Volumes:", "SIGQUIT", "Healthcheck:", "30s", "retries"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot: %s", want, buf.String())
+		}
+	}
+}
+
 func TestPrintLayers_JSON(t *testing.T) {
 	layers := []format.LayerData{
 		{Index: 0, Digest: "sha256:abc", Size: 1024, Command: "ADD file:..."},
@@ -105,3 +131,102 @@ func TestHumanSize(t *testing.T) {
 		}
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	cases := []struct {
+		in   string
+		want int64
+	}{
+		{"512", 512},
+		{"512B", 512},
+		{"1KB", 1024},
+		{"1.5 GiB", 3 << 29},
+		{"10g", 10 << 30},
+	}
+	for _, tc := range cases {
+		got, err := format.ParseSize(tc.in)
+		if err != nil || got != tc.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", tc.in, got, err, tc.want)
+		}
+	}
+	for _, bad := range []string{"", "GB", "10XB", "-1"} {
+		if _, err := format.ParseSize(bad); err == nil {
+			t.Errorf("ParseSize(%q): expected error", bad)
+		}
+	}
+}
+
+func TestPrintLayers_HumanFiles(t *testing.T) {
+	layers := []format.LayerData{
+		{Index: 0, Digest: "sha256:abc", Size: 10, Files: []format.FileChange{
+			{Path: "/etc/hosts", Kind: "modified"},
+			{Path: "/etc/passwd", Kind: "deleted"},
+		}},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintLayers(&buf, layers, format.Human); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "M /etc/hosts") {
+		t.Errorf("human output missing modified file\ngot: %s", out)
+	}
+	if !strings.Contains(out, "D /etc/passwd") {
+		t.Errorf("human output missing deleted file\ngot: %s", out)
+	}
+}
+
+func TestPrintDiff_Human(t *testing.T) {
+	one, two, empty := "1", "2", ""
+	data := format.DiffData{
+		ImageA: "app:1",
+		ImageB: "app:2",
+		Config: []format.ConfigChange{
+			{Field: "env.VERSION", A: &one, B: &two},
+			{Field: "env.DEBUG", A: &empty},
+		},
+		Layers: format.LayerDiff{Shared: 1, OnlyB: []string{"sha256:def"}},
+		Files:  []format.FileChange{{Path: "/app/bin", Kind: "modified"}},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintDiff(&buf, data, format.Human); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"env.VERSION", `env.DEBUG    ""  <none>`, "+ sha256:def", "M /app/bin"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("human output missing %q\ngot: %s", want, out)
+		}
+	}
+}
+
+func TestPrintError_JSON(t *testing.T) {
+	data := format.ErrorData{Error: "image not found", Kind: "not_found", ExitCode: 2}
+	var buf bytes.Buffer
+	if err := format.PrintError(&buf, data, format.JSON); err != nil {
+		t.Fatal(err)
+	}
+	var got format.ErrorData
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\nraw: %s", err, buf.String())
+	}
+	if got != data {
+		t.Errorf("got %+v, want %+v", got, data)
+	}
+}
+
+func TestPrintFiles_Human(t *testing.T) {
+	data := []format.FileData{
+		{Path: "/bin", Type: "symlink", Mode: "lrwxrwxrwx", Owner: "root:root", LinkName: "usr/bin"},
+		{Path: "/etc/hosts", Type: "file", Mode: "-rw-r--r--", Owner: "0:0", Size: 9, ModTime: "2026-01-02 03:04:05"},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintFiles(&buf, data, format.Human); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"/bin -> usr/bin", "-rw-r--r--", "2026-01-02 03:04:05"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("output missing %q\ngot: %s", want, buf.String())
+		}
+	}
+}
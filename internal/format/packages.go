@@ -0,0 +1,34 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// PackageData is one installed package reported by `imgutil packages`.
+type PackageData struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Arch        string `json:"arch"`
+	Source      string `json:"source"`
+	Type        string `json:"type"` // "deb", "apk", "rpm", "go", "python", "npm" or "java"
+	Path        string `json:"path"` // file that records it
+	Layer       int    `json:"layer"`
+	LayerDigest string `json:"layer_digest"`
+}
+
+// PrintPackages writes an image's installed packages to w in the requested format.
+func PrintPackages(w io.Writer, data []PackageData, f Format) error {
+	return render(w, data, f, func() error { return printPackagesHuman(w, data) })
+}
+
+func printPackagesHuman(w io.Writer, data []PackageData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "NAME\tVERSION\tARCH\tSOURCE\tTYPE\tLAYER\tPATH\n")
+	for _, p := range data {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			p.Name, orNone(p.Version), orNone(p.Arch), orNone(p.Source), p.Type, p.Layer+1, p.Path)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,47 @@
+package format
+
+import (
+	"fmt"
+	"io"
+)
+
+// TagsData holds the tags of a repository for output.
+type TagsData struct {
+	Repository string   `json:"repository"`
+	Tags       []string `json:"tags"`
+}
+
+// CatalogData holds one listing of a registry's repositories for output.
+type CatalogData struct {
+	Registry     string   `json:"registry"`
+	Repositories []string `json:"repositories"`
+	// Next is the value to pass as --last for the following page, set when
+	// the page came back full.
+	Next string `json:"next,omitempty"`
+}
+
+// PrintTags writes a repository's tags to w in the requested format. Human
+// output is one tag per line, for piping into other tools.
+func PrintTags(w io.Writer, data TagsData, f Format) error {
+	return render(w, data, f, func() error {
+		for _, t := range data.Tags {
+			_, _ = fmt.Fprintln(w, t)
+		}
+		return nil
+	})
+}
+
+// PrintCatalog writes a registry's repositories to w in the requested format.
+// Human output is one repository per line, followed by a hint when there is
+// another page.
+func PrintCatalog(w io.Writer, data CatalogData, f Format) error {
+	return render(w, data, f, func() error {
+		for _, r := range data.Repositories {
+			_, _ = fmt.Fprintln(w, r)
+		}
+		if data.Next != "" {
+			_, _ = fmt.Fprintf(w, "# more repositories: --last %s\n", data.Next)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,219 @@
+package format
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Renderer is a machine-readable output format. Human output is not a
+// Renderer: each Print function supplies its own human rendering.
+type Renderer struct {
+	// Render writes v to w. arg is the text after "=" in the format, if any.
+	Render func(w io.Writer, v any, arg string) error
+	// ParseArg validates arg when the format is parsed. A nil ParseArg means
+	// the format takes no argument.
+	ParseArg func(arg string) error
+}
+
+var renderers = map[string]Renderer{}
+
+// Register makes a renderer available as --output name (or name=arg).
+// Registering a name twice replaces the earlier renderer.
+func Register(name string, r Renderer) {
+	renderers[name] = r
+}
+
+// Names returns the registered format names, including human, sorted.
+func Names() []string {
+	names := []string{string(Human)}
+	for name := range renderers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func init() {
+	Register(string(JSON), Renderer{Render: func(w io.Writer, v any, _ string) error { return printJSON(w, v) }})
+	Register(string(YAML), Renderer{Render: func(w io.Writer, v any, _ string) error { return printYAML(w, v) }})
+	Register(string(CSV), Renderer{Render: func(w io.Writer, v any, _ string) error { return printCSV(w, v) }})
+	Register("template", Renderer{
+		Render: printTemplate,
+		ParseArg: func(arg string) error {
+			_, err := parseTemplate(arg)
+			return err
+		},
+	})
+	Register("jsonpath", Renderer{
+		Render: printJSONPath,
+		ParseArg: func(arg string) error {
+			_, err := parseJSONPath(arg)
+			return err
+		},
+	})
+}
+
+// render writes v in format f, calling human for the default rendering.
+func render(w io.Writer, v any, f Format, human func() error) error {
+	name, arg, _ := strings.Cut(string(f), "=")
+	if name == string(Human) || name == "" {
+		return human()
+	}
+	r, ok := renderers[name]
+	if !ok {
+		return fmt.Errorf("unknown output format %q", f)
+	}
+	return r.Render(w, v, arg)
+}
+
+func printJSON(w io.Writer, v any) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// printYAML renders v via its JSON form, so YAML keys match the JSON field
+// names and keep struct field order.
+func printYAML(w io.Writer, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	node, err := yamlNode(dec)
+	if err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(node); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// yamlNode converts the next JSON value from dec into a YAML node.
+func yamlNode(dec *json.Decoder) (*yaml.Node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if t == '{' {
+			node.Kind, node.Tag = yaml.MappingNode, "!!map"
+		}
+		for dec.More() {
+			if node.Kind == yaml.MappingNode {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key.(string)})
+			}
+			child, err := yamlNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		if _, err := dec.Token(); err != nil { // closing delimiter
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: t}, nil
+	case json.Number:
+		tag := "!!int"
+		if strings.ContainsAny(t.String(), ".eE") {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: t.String()}, nil
+	case bool:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(t)}, nil
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	}
+	return nil, fmt.Errorf("unexpected JSON token %v", tok)
+}
+
+// printCSV renders a list of structs as CSV with one column per JSON field.
+// Nested values (lists, maps) are written as JSON within their cell.
+func printCSV(w io.Writer, v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice || rv.Type().Elem().Kind() != reflect.Struct {
+		return errors.New("csv output is only supported for list output, such as layers")
+	}
+
+	fields := csvFields(rv.Type().Elem())
+	cw := csv.NewWriter(w)
+	header := make([]string, 0, len(fields))
+	for _, f := range fields {
+		header = append(header, f.name)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i := 0; i < rv.Len(); i++ {
+		row := make([]string, 0, len(fields))
+		for _, f := range fields {
+			cell, err := csvCell(rv.Index(i).Field(f.index))
+			if err != nil {
+				return err
+			}
+			row = append(row, cell)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+type csvField struct {
+	name  string
+	index int
+}
+
+func csvFields(t reflect.Type) []csvField {
+	var fields []csvField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, csvField{name: name, index: i})
+	}
+	return fields
+}
+
+func csvCell(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Struct, reflect.Pointer, reflect.Interface:
+		if v.IsZero() {
+			return "", nil
+		}
+		b, err := json.Marshal(v.Interface())
+		return string(b), err
+	}
+	return fmt.Sprint(v.Interface()), nil
+}
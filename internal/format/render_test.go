@@ -0,0 +1,83 @@
+package format_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+func TestPrintInspect_YAML(t *testing.T) {
+	data := format.InspectData{
+		Reference: "alpine:latest",
+		Digest:    "sha256:abc",
+		SizeBytes: 1024,
+		Labels:    map[string]string{"version": "true"},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintInspect(&buf, data, format.YAML); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{"reference: alpine:latest\n", "size_bytes: 1024\n", `version: "true"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("YAML output missing %q\ngot: %s", want, out)
+		}
+	}
+	if strings.Index(out, "reference:") > strings.Index(out, "digest:") {
+		t.Errorf("YAML output does not keep field order\ngot: %s", out)
+	}
+}
+
+func TestPrintLayers_CSV(t *testing.T) {
+	layers := []format.LayerData{
+		{Index: 0, Digest: "sha256:abc", Size: 1024, Command: `RUN echo "a, b"`},
+		{Index: 1, Digest: "sha256:def", Size: 2048, Files: []format.FileChange{{Path: "/a", Kind: "added"}}},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintLayers(&buf, layers, format.CSV); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header + 2 rows\ngot: %s", len(lines), buf.String())
+	}
+	if lines[0] != "index,digest,size,media_type,compression,command,files" {
+		t.Errorf("got header %q", lines[0])
+	}
+	if lines[1] != `0,sha256:abc,1024,,,"RUN echo ""a, b""",` {
+		t.Errorf("got row %q", lines[1])
+	}
+}
+
+func TestPrintInspect_CSVRejected(t *testing.T) {
+	var buf bytes.Buffer
+	if err := format.PrintInspect(&buf, format.InspectData{}, format.CSV); err == nil {
+		t.Error("expected error rendering a single object as CSV")
+	}
+}
+
+func TestRegister(t *testing.T) {
+	format.Register("upper", format.Renderer{
+		Render: func(w io.Writer, v any, _ string) error {
+			_, err := io.WriteString(w, strings.ToUpper(v.(format.InspectData).Reference))
+			return err
+		},
+	})
+	f, err := format.Parse("upper")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := format.PrintInspect(&buf, format.InspectData{Reference: "alpine"}, f); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "ALPINE" {
+		t.Errorf("got %q, want ALPINE", buf.String())
+	}
+	if _, err := format.Parse("upper=x"); err == nil {
+		t.Error("expected error passing an argument to a format that takes none")
+	}
+}
@@ -0,0 +1,50 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+)
+
+// SecretData is one secret found by `imgutil secrets`.
+type SecretData struct {
+	RuleID      string `json:"rule_id"`
+	Description string `json:"description"`
+	Source      string `json:"source"` // "file", "env" or "history"
+	Location    string `json:"location"`
+	Path        string `json:"path,omitempty"`
+	Line        int    `json:"line,omitempty"`
+	Layer       int    `json:"layer"` // -1 when the secret is not in a layer
+	LayerDigest string `json:"layer_digest,omitempty"`
+	// Removed is set for files a later layer deleted or replaced; they can
+	// still be read from the layer.
+	Removed bool   `json:"removed"`
+	Snippet string `json:"snippet"` // redacted
+}
+
+// PrintSecrets writes the secrets found in an image to w in the requested format.
+func PrintSecrets(w io.Writer, data []SecretData, f Format) error {
+	return render(w, data, f, func() error { return printSecretsHuman(w, data) })
+}
+
+func printSecretsHuman(w io.Writer, data []SecretData) error {
+	if len(data) == 0 {
+		_, _ = fmt.Fprintf(w, "no secrets found\n")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "RULE\tLAYER\tLOCATION\tSNIPPET\n")
+	for _, s := range data {
+		layer := "-"
+		if s.Layer >= 0 {
+			layer = strconv.Itoa(s.Layer + 1)
+		}
+		location := s.Location
+		if s.Removed {
+			location += " (removed)"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", s.RuleID, layer, location, s.Snippet)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,67 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are available to --output template=... in addition to the
+// text/template builtins.
+var templateFuncs = template.FuncMap{
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"join":  strings.Join,
+	"size":  HumanSize,
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+func parseTemplate(tmpl string) (*template.Template, error) {
+	t, err := template.New("output").Funcs(templateFuncs).Option("missingkey=zero").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return t, nil
+}
+
+// printTemplate executes tmpl against v, one line per element when v is a
+// list (like `docker ps --format`) and once otherwise. Fields use the Go
+// names of the data types, e.g. {{.Digest}} or {{index .Labels "version"}}.
+func printTemplate(w io.Writer, v any, tmpl string) error {
+	t, err := parseTemplate(tmpl)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice {
+		return executeLine(w, t, v)
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := executeLine(w, t, rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func executeLine(w io.Writer, t *template.Template, v any) error {
+	if err := t.Execute(w, v); err != nil {
+		return fmt.Errorf("executing output template: %w", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func printJSONPath(w io.Writer, v any, expr string) error {
+	p, err := parseJSONPath(expr)
+	if err != nil {
+		return err
+	}
+	return p.execute(w, v)
+}
@@ -0,0 +1,96 @@
+package format_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/format"
+)
+
+func TestParse(t *testing.T) {
+	valid := []string{"human", "json", "template={{.Digest}}", "jsonpath={.digest}"}
+	for _, s := range valid {
+		if _, err := format.Parse(s); err != nil {
+			t.Errorf("Parse(%q): unexpected error %v", s, err)
+		}
+	}
+	invalid := []string{"", "xml", "JSON", "template={{.Digest", "jsonpath={.digest", "jsonpath={digest}"}
+	for _, s := range invalid {
+		if _, err := format.Parse(s); err == nil {
+			t.Errorf("Parse(%q): expected error", s)
+		}
+	}
+}
+
+func TestPrintInspect_Template(t *testing.T) {
+	data := format.InspectData{
+		Digest: "sha256:abc",
+		Labels: map[string]string{"version": "1.2.3"},
+	}
+	f, err := format.Parse(`template={{.Digest}} {{index .Labels "version"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := format.PrintInspect(&buf, data, f); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "sha256:abc 1.2.3\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintLayers_TemplatePerItem(t *testing.T) {
+	layers := []format.LayerData{
+		{Index: 0, Digest: "sha256:a", Size: 1024},
+		{Index: 1, Digest: "sha256:b", Size: 2048},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintLayers(&buf, layers, "template={{.Digest}} {{size .Size}}"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "sha256:a 1.00 KB\nsha256:b 2.00 KB\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestPrintInspect_JSONPath(t *testing.T) {
+	data := format.InspectData{
+		Digest: "sha256:abc",
+		Env:    []string{"A=1", "B=2"},
+		Labels: map[string]string{"org.opencontainers.image.version": "1.2.3"},
+	}
+	cases := []struct {
+		expr string
+		want string
+	}{
+		{"{.digest}", "sha256:abc\n"},
+		{"{.env[1]}", "B=2\n"},
+		{"{.env[*]}", "A=1 B=2\n"},
+		{"version={.labels['org.opencontainers.image.version']}", "version=1.2.3\n"},
+		{"{.labels.missing}", "\n"},
+	}
+	for _, tc := range cases {
+		var buf bytes.Buffer
+		if err := format.PrintInspect(&buf, data, format.Format("jsonpath="+tc.expr)); err != nil {
+			t.Fatalf("%s: %v", tc.expr, err)
+		}
+		if buf.String() != tc.want {
+			t.Errorf("%s: got %q, want %q", tc.expr, buf.String(), tc.want)
+		}
+	}
+}
+
+func TestPrintLayers_JSONPath(t *testing.T) {
+	layers := []format.LayerData{
+		{Index: 0, Digest: "sha256:a"},
+		{Index: 1, Digest: "sha256:b"},
+	}
+	var buf bytes.Buffer
+	if err := format.PrintLayers(&buf, layers, "jsonpath={[*].digest}"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := buf.String(), "sha256:a sha256:b\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
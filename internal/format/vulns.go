@@ -0,0 +1,42 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// VulnData is one advisory affecting an installed package, reported by
+// `imgutil vulns`.
+type VulnData struct {
+	ID           string   `json:"id"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Severity     string   `json:"severity"` // "unknown", "low", "medium", "high" or "critical"
+	Package      string   `json:"package"`
+	Version      string   `json:"version"`
+	FixedVersion string   `json:"fixed_version"`
+	Type         string   `json:"type"`
+	Path         string   `json:"path"`
+	Layer        int      `json:"layer"`
+	LayerDigest  string   `json:"layer_digest"`
+	Summary      string   `json:"summary,omitempty"`
+}
+
+// PrintVulns writes the advisories affecting an image to w in the requested format.
+func PrintVulns(w io.Writer, data []VulnData, f Format) error {
+	return render(w, data, f, func() error { return printVulnsHuman(w, data) })
+}
+
+func printVulnsHuman(w io.Writer, data []VulnData) error {
+	if len(data) == 0 {
+		_, _ = fmt.Fprintf(w, "no known vulnerabilities\n")
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "ID\tSEVERITY\tPACKAGE\tVERSION\tFIXED\tTYPE\tLAYER\n")
+	for _, v := range data {
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			v.ID, v.Severity, v.Package, v.Version, orNone(v.FixedVersion), v.Type, v.Layer+1)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,34 @@
+package format
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// WhichData is one layer's change to a path matched by `imgutil which`.
+type WhichData struct {
+	Layer   int    `json:"layer"`
+	Digest  string `json:"digest"`
+	Path    string `json:"path"`
+	Kind    string `json:"kind"` // "added", "modified" or "deleted"
+	Command string `json:"command"`
+}
+
+// PrintWhich writes the layers that touched a path to w in the requested format.
+func PrintWhich(w io.Writer, data []WhichData, f Format) error {
+	return render(w, data, f, func() error { return printWhichHuman(w, data) })
+}
+
+func printWhichHuman(w io.Writer, data []WhichData) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(tw, "#\tDIGEST\tCHANGE\tPATH\tCOMMAND\n")
+	for _, d := range data {
+		digest := d.Digest
+		if len(digest) > 19 {
+			digest = digest[:19]
+		}
+		_, _ = fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", d.Layer+1, digest, changeMarker(d.Kind), d.Path, d.Command)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,107 @@
+package image
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// gzipMagic is the two-byte header that starts every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// fromArchive loads an image from a `docker save` style tarball. ref has the
+// form path[:tag]; the tag is required when the archive holds several images.
+// Gzip-compressed archives are decompressed transparently.
+func (l *Loader) fromArchive(ref string) (*Resolved, error) {
+	path, rawTag := splitArchiveRef(ref)
+	opener := archiveOpener(path)
+
+	manifest, err := tarball.LoadManifest(opener)
+	if err != nil {
+		return nil, err
+	}
+
+	var tag *name.Tag
+	if rawTag != "" {
+		t, err := name.NewTag(rawTag)
+		if err != nil {
+			return nil, &Error{Kind: ErrInvalidReference, Err: fmt.Errorf("invalid tag %q: %w", rawTag, err)}
+		}
+		if !archiveHasTag(manifest, t) {
+			return nil, &Error{Kind: ErrNotFound, Err: fmt.Errorf("tag %s not found in %s", t, path)}
+		}
+		tag = &t
+	} else if len(manifest) > 1 {
+		var tags []string
+		for _, desc := range manifest {
+			tags = append(tags, desc.RepoTags...)
+		}
+		return nil, fmt.Errorf("%s contains %d images; choose one of: %s",
+			path, len(manifest), strings.Join(tags, ", "))
+	}
+
+	img, err := tarball.Image(opener, tag)
+	if err != nil {
+		return nil, err
+	}
+	return &Resolved{Image: img}, nil
+}
+
+func archiveHasTag(manifest tarball.Manifest, tag name.Tag) bool {
+	for _, desc := range manifest {
+		for _, repoTag := range desc.RepoTags {
+			if t, err := name.NewTag(repoTag); err == nil && t.Name() == tag.Name() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// archiveOpener opens path, wrapping it in a gzip reader when the file starts
+// with the gzip magic bytes.
+func archiveOpener(path string) tarball.Opener {
+	return func() (io.ReadCloser, error) {
+		f, err := os.Open(path) //nolint:gosec // G304: path is the user's own CLI argument
+		if err != nil {
+			return nil, err
+		}
+		br := bufio.NewReader(f)
+		magic, err := br.Peek(len(gzipMagic))
+		if err != nil || string(magic) != string(gzipMagic) {
+			return struct {
+				io.Reader
+				io.Closer
+			}{br, f}, nil
+		}
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			_ = f.Close()
+			return nil, fmt.Errorf("reading gzip archive: %w", err)
+		}
+		return struct {
+			io.Reader
+			io.Closer
+		}{zr, f}, nil
+	}
+}
+
+// splitArchiveRef splits "path:tag" where path names an existing file and tag
+// is an image reference such as "app:v1" or "registry:5000/app:v1". Both may
+// contain colons, so the split is at the last colon with an existing file
+// before it: "./build:1/img.tar:app:v1" is the archive ./build:1/img.tar
+// holding app:v1. When there is none, ref is all path.
+func splitArchiveRef(ref string) (path, tag string) {
+	for i := len(ref); i > 0; i = strings.LastIndexByte(ref[:i], ':') {
+		if info, err := os.Stat(ref[:i]); err == nil && !info.IsDir() {
+			return ref[:i], strings.TrimPrefix(ref[i:], ":")
+		}
+	}
+	return ref, ""
+}
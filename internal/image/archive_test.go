@@ -0,0 +1,129 @@
+package image_test
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// writeArchive saves images to a `docker save` style tarball keyed by tag.
+func writeArchive(t *testing.T, images map[string]v1.Image) string {
+	t.Helper()
+	refs := map[name.Tag]v1.Image{}
+	for tag, img := range images {
+		ref, err := name.NewTag(tag)
+		if err != nil {
+			t.Fatal(err)
+		}
+		refs[ref] = img
+	}
+	path := filepath.Join(t.TempDir(), "images.tar")
+	if err := tarball.MultiWriteToFile(path, refs); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoader_Archive_SingleImage(t *testing.T) {
+	want := randomImage(t)
+	path := writeArchive(t, map[string]v1.Image{"app:v1": want})
+	l := image.NewLoaderWithFetchers(nil, nil)
+
+	got, err := l.Load("docker-archive:"+path, image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestOf(t, got) != digestOf(t, want) {
+		t.Error("expected the archived image")
+	}
+}
+
+func TestLoader_Archive_ByTag(t *testing.T) {
+	want := randomImage(t)
+	path := writeArchive(t, map[string]v1.Image{"app:v1": randomImage(t), "app:v2": want})
+	l := image.NewLoaderWithFetchers(nil, nil)
+
+	if _, err := l.Load("tarball:"+path, image.Auto); err == nil {
+		t.Error("expected error choosing between several images without a tag")
+	}
+
+	got, err := l.Load("tarball:"+path+":app:v2", image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestOf(t, got) != digestOf(t, want) {
+		t.Error("expected image tagged app:v2")
+	}
+}
+
+func TestLoader_Archive_Gzip(t *testing.T) {
+	want := randomImage(t)
+	plain := writeArchive(t, map[string]v1.Image{"app:v1": want})
+
+	raw, err := os.ReadFile(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := plain + ".gz"
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := gzip.NewWriter(f)
+	if _, err := zw.Write(raw); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	l := image.NewLoaderWithFetchers(nil, nil)
+	got, err := l.Load("docker-archive:"+path, image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestOf(t, got) != digestOf(t, want) {
+		t.Error("expected the archived image")
+	}
+}
+
+func TestLoader_Archive_RejectsLocalOnly(t *testing.T) {
+	l := image.NewLoaderWithFetchers(nil, nil)
+	if _, err := l.Load("docker-archive:app.tar", image.LocalOnly); err == nil {
+		t.Error("expected error combining a docker-archive: reference with LocalOnly")
+	}
+}
+
+func TestLoader_Archive_ColonInPath(t *testing.T) {
+	want := randomImage(t)
+	src := writeArchive(t, map[string]v1.Image{"app:v1": randomImage(t), "registry:5000/app:v2": want})
+	dir := filepath.Join(t.TempDir(), "build:1")
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "img.tar")
+	if err := os.Rename(src, path); err != nil {
+		t.Fatal(err)
+	}
+	l := image.NewLoaderWithFetchers(nil, nil)
+
+	got, err := l.Load("docker-archive:"+path+":registry:5000/app:v2", image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestOf(t, got) != digestOf(t, want) {
+		t.Error("expected image tagged registry:5000/app:v2")
+	}
+	if _, err := l.Load("docker-archive:"+path+":app:v3", image.Auto); image.KindName(err) != "not_found" {
+		t.Errorf("got %v, want tag app:v3 not found in %s", err, path)
+	}
+}
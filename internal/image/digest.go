@@ -0,0 +1,190 @@
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Descriptor describes what a reference resolves to, without downloading
+// the image.
+type Descriptor struct {
+	// Source is where the reference was found: "daemon", "registry",
+	// "oci-layout" or "archive".
+	Source string
+	// Digest, MediaType and Size describe the manifest (or index) the
+	// reference names. Daemon images have no manifest, so these are empty.
+	Digest    string
+	MediaType string
+	Size      int64
+	// ImageID and RepoDigests are reported for daemon images only.
+	ImageID     string
+	RepoDigests []string
+}
+
+// daemonInfo is what the daemon knows about a local image.
+type daemonInfo struct {
+	ID          string
+	RepoDigests []string
+	Platform    *v1.Platform
+}
+
+// headRegistry fetches the descriptor ref names with a HEAD request, falling
+// back to GET for registries that do not support HEAD on manifests. Other
+// failures are returned as they are, so they keep their kind.
+func (l *Loader) headRegistry(ref name.Reference) (*v1.Descriptor, error) {
+	desc, err := remote.Head(ref, l.remoteOptions()...)
+	if err == nil {
+		return desc, nil
+	}
+	if !headUnsupported(err) {
+		return nil, err
+	}
+	l.logger.Printf("HEAD %s: %v; retrying with GET", ref, err)
+	got, err := remote.Get(ref, l.remoteOptions()...)
+	if err != nil {
+		return nil, err
+	}
+	return &got.Descriptor, nil
+}
+
+// headUnsupported reports whether err is a registry refusing HEAD on the
+// manifest endpoint, rather than a missing image or a failed request.
+func headUnsupported(err error) bool {
+	var terr *transport.Error
+	if !errors.As(err, &terr) {
+		return false
+	}
+	switch terr.StatusCode {
+	case http.StatusMethodNotAllowed, http.StatusBadRequest, http.StatusNotImplemented:
+		return true
+	}
+	return false
+}
+
+// inspectDaemon asks the Docker daemon for the image ID and repo digests of ref.
+func inspectDaemon(ref name.Reference) (*daemonInfo, error) {
+	cli, err := client.NewClientWithOpts(client.FromEnv, client.WithAPIVersionNegotiation())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = cli.Close() }()
+	resp, err := cli.ImageInspect(context.Background(), ref.String())
+	if err != nil {
+		return nil, err
+	}
+	return &daemonInfo{
+		ID:          resp.ID,
+		RepoDigests: resp.RepoDigests,
+		Platform:    &v1.Platform{OS: resp.Os, Architecture: resp.Architecture, Variant: resp.Variant},
+	}, nil
+}
+
+// Describe resolves rawRef to a Descriptor. Registry references cost a single
+// HEAD request; daemon images are inspected rather than exported. OCI layout
+// and archive references are read from disk.
+func (l *Loader) Describe(rawRef string, src Source) (*Descriptor, error) {
+	scheme, _, err := sourceForRef(rawRef, src)
+	if err != nil {
+		return nil, err
+	}
+	if scheme == OCILayout || scheme == Archive {
+		res, err := l.Resolve(rawRef, src)
+		if err != nil {
+			return nil, err
+		}
+		d, err := describeImage(res.Image)
+		if err != nil {
+			return nil, err
+		}
+		d.Source = "oci-layout"
+		if scheme == Archive {
+			d.Source = "archive"
+		}
+		return d, nil
+	}
+
+	ref, err := name.ParseReference(rawRef)
+	if err != nil {
+		return nil, &Error{Kind: ErrInvalidReference, Err: fmt.Errorf("invalid image reference %q: %w", rawRef, err)}
+	}
+
+	switch src {
+	case LocalOnly:
+		l.logger.Printf("inspecting %s in daemon (local only)", ref)
+		d, err := l.describeDaemon(ref)
+		if err != nil {
+			return nil, Classify(fmt.Errorf("image %q not found in local daemon: %w", rawRef, err))
+		}
+		return d, nil
+
+	case RemoteOnly:
+		l.logger.Printf("resolving %s in registry (remote only)", ref)
+		d, err := l.describeRegistry(ref)
+		if err != nil {
+			return nil, Classify(fmt.Errorf("image %q not found in remote registry: %w", rawRef, err))
+		}
+		return d, nil
+
+	default: // Auto
+		l.logger.Printf("trying daemon for %s", ref)
+		d, err := l.describeDaemon(ref)
+		if err == nil {
+			return d, nil
+		}
+		l.logger.Printf("daemon: %v; falling back to registry", err)
+		d, err = l.describeRegistry(ref)
+		if err != nil {
+			return nil, Classify(fmt.Errorf("image %q not found locally or in remote registry: %w", rawRef, err))
+		}
+		return d, nil
+	}
+}
+
+func (l *Loader) describeDaemon(ref name.Reference) (*Descriptor, error) {
+	info, err := l.fromDaemonInfo(ref)
+	if err != nil {
+		return nil, err
+	}
+	if l.platform != nil && (info.Platform == nil || !info.Platform.Satisfies(*l.platform)) {
+		return nil, fmt.Errorf("daemon image is %s, not %s", info.Platform, l.platform)
+	}
+	return &Descriptor{Source: "daemon", ImageID: info.ID, RepoDigests: info.RepoDigests}, nil
+}
+
+func (l *Loader) describeRegistry(ref name.Reference) (*Descriptor, error) {
+	desc, err := l.fromRegistryHead(ref)
+	if err != nil {
+		return nil, err
+	}
+	return &Descriptor{
+		Source:    "registry",
+		Digest:    desc.Digest.String(),
+		MediaType: string(desc.MediaType),
+		Size:      desc.Size,
+	}, nil
+}
+
+// describeImage builds a Descriptor from an already-loaded image.
+func describeImage(img v1.Image) (*Descriptor, error) {
+	digest, err := img.Digest()
+	if err != nil {
+		return nil, fmt.Errorf("reading digest: %w", err)
+	}
+	mediaType, err := img.MediaType()
+	if err != nil {
+		return nil, fmt.Errorf("reading media type: %w", err)
+	}
+	raw, err := img.RawManifest()
+	if err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+	return &Descriptor{Digest: digest.String(), MediaType: string(mediaType), Size: int64(len(raw))}, nil
+}
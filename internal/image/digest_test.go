@@ -0,0 +1,104 @@
+package image_test
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func TestLoader_Describe_FallsBackToGet(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	var (
+		blockHead   bool
+		heads, gets int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") {
+			switch r.Method {
+			case http.MethodHead:
+				if blockHead {
+					heads++
+					w.WriteHeader(http.StatusMethodNotAllowed)
+					return
+				}
+			case http.MethodGet:
+				gets++
+			}
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	rawRef := strings.TrimPrefix(srv.URL, "http://") + "/app:latest"
+	ref, err := name.ParseReference(rawRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := randomImage(t)
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatal(err)
+	}
+	want, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockHead, gets = true, 0
+
+	d, err := image.NewLoader().Describe(rawRef, image.RemoteOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Digest != want.String() {
+		t.Errorf("got digest %s, want %s", d.Digest, want)
+	}
+	if heads == 0 || gets == 0 {
+		t.Errorf("got %d HEAD and %d GET requests, want a HEAD then a GET", heads, gets)
+	}
+}
+
+func TestLoader_Describe_NoFallbackOnNotFound(t *testing.T) {
+	reg := registry.New(registry.Logger(log.New(io.Discard, "", 0)))
+	var gets int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") && r.Method == http.MethodGet {
+			gets++
+		}
+		reg.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	rawRef := strings.TrimPrefix(srv.URL, "http://") + "/app:missing"
+	_, err := image.NewLoader().Describe(rawRef, image.RemoteOnly)
+	if err == nil {
+		t.Fatal("expected an error for a missing tag")
+	}
+	if kind := image.KindName(err); kind != "not_found" {
+		t.Errorf("got kind %q, want not_found: %v", kind, err)
+	}
+	if gets != 0 {
+		t.Errorf("got %d GET requests after a 404 HEAD, want none", gets)
+	}
+}
+
+func TestLoader_Describe_DaemonPlatformMismatch(t *testing.T) {
+	img := randomImage(t) // random images have no platform in their config
+	l := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) { return img, nil },
+		func(_ name.Reference) (v1.Image, error) { return nil, errors.New("no remote") },
+	)
+	l.SetPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})
+
+	if _, err := l.Describe("alpine:latest", image.LocalOnly); err == nil {
+		t.Error("expected error for daemon image with the wrong platform")
+	}
+}
@@ -0,0 +1,108 @@
+package image
+
+import (
+	"errors"
+	"io/fs"
+	"net"
+	"net/http"
+
+	cerrdefs "github.com/containerd/errdefs"
+	"github.com/docker/docker/client"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+)
+
+// Error kinds returned (wrapped in *Error) by the loader. Match them with errors.Is.
+var (
+	ErrNotFound         = errors.New("image not found")
+	ErrUnauthorized     = errors.New("unauthorized")
+	ErrUnreachable      = errors.New("registry or daemon unreachable")
+	ErrInvalidReference = errors.New("invalid image reference")
+)
+
+// Error is a loader failure tagged with one of the Err* kinds. Its message is
+// that of the underlying error; the kind is only visible through errors.Is.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string { return e.Err.Error() }
+
+func (e *Error) Unwrap() []error { return []error{e.Kind, e.Err} }
+
+// Classify tags err with the kind of failure it represents, based on registry
+// status and error codes, daemon errors and network failures. Errors that
+// match no kind, or are already tagged, are returned unchanged.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var tagged *Error
+	if errors.As(err, &tagged) {
+		return err
+	}
+	if kind := kindOf(err); kind != nil {
+		return &Error{Kind: kind, Err: err}
+	}
+	return err
+}
+
+// KindName classifies err and returns the name of its kind as reported in
+// JSON output: "not_found", "unreachable", "unauthorized", "invalid_reference",
+// or "error" when it matches none.
+func KindName(err error) string {
+	err = Classify(err)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return "not_found"
+	case errors.Is(err, ErrUnreachable):
+		return "unreachable"
+	case errors.Is(err, ErrUnauthorized):
+		return "unauthorized"
+	case errors.Is(err, ErrInvalidReference):
+		return "invalid_reference"
+	}
+	return "error"
+}
+
+func kindOf(err error) error {
+	var terr *transport.Error
+	if errors.As(err, &terr) {
+		for _, d := range terr.Errors {
+			switch d.Code {
+			case transport.ManifestUnknownErrorCode, transport.NameUnknownErrorCode, transport.BlobUnknownErrorCode:
+				return ErrNotFound
+			case transport.UnauthorizedErrorCode, transport.DeniedErrorCode:
+				return ErrUnauthorized
+			case transport.NameInvalidErrorCode, transport.TagInvalidErrorCode:
+				return ErrInvalidReference
+			case transport.TooManyRequestsErrorCode, transport.UnavailableErrorCode:
+				return ErrUnreachable
+			}
+		}
+		switch {
+		case terr.StatusCode == http.StatusNotFound:
+			return ErrNotFound
+		case terr.StatusCode == http.StatusUnauthorized, terr.StatusCode == http.StatusForbidden:
+			return ErrUnauthorized
+		case terr.StatusCode == http.StatusTooManyRequests, terr.StatusCode >= 500:
+			return ErrUnreachable
+		}
+		return nil
+	}
+
+	switch {
+	case client.IsErrConnectionFailed(err), cerrdefs.IsUnavailable(err):
+		return ErrUnreachable
+	case cerrdefs.IsNotFound(err), errors.Is(err, fs.ErrNotExist):
+		return ErrNotFound
+	case cerrdefs.IsUnauthorized(err), cerrdefs.IsPermissionDenied(err):
+		return ErrUnauthorized
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return ErrUnreachable
+	}
+	return nil
+}
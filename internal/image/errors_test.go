@@ -0,0 +1,96 @@
+package image_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"manifest unknown", &transport.Error{
+			StatusCode: http.StatusNotFound,
+			Errors:     []transport.Diagnostic{{Code: transport.ManifestUnknownErrorCode}},
+		}, image.ErrNotFound},
+		{"bare 404", &transport.Error{StatusCode: http.StatusNotFound}, image.ErrNotFound},
+		{"unauthorized", &transport.Error{
+			StatusCode: http.StatusUnauthorized,
+			Errors:     []transport.Diagnostic{{Code: transport.UnauthorizedErrorCode}},
+		}, image.ErrUnauthorized},
+		{"server error", &transport.Error{StatusCode: http.StatusBadGateway}, image.ErrUnreachable},
+		{"connection refused", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, image.ErrUnreachable},
+		{"missing file", fmt.Errorf("opening: %w", os.ErrNotExist), image.ErrNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := image.Classify(fmt.Errorf("wrapped: %w", tc.err))
+			if !errors.Is(err, tc.want) {
+				t.Errorf("Classify(%v) is not %v", tc.err, tc.want)
+			}
+			if !strings.HasPrefix(err.Error(), "wrapped: ") {
+				t.Errorf("Classify changed the message: %q", err.Error())
+			}
+		})
+	}
+
+	plain := errors.New("something else")
+	if got := image.Classify(plain); got != plain {
+		t.Errorf("Classify of an unrecognised error = %v, want it unchanged", got)
+	}
+}
+
+func TestKindName(t *testing.T) {
+	cases := map[error]string{
+		&transport.Error{StatusCode: http.StatusNotFound}:     "not_found",
+		&transport.Error{StatusCode: http.StatusUnauthorized}: "unauthorized",
+		&transport.Error{StatusCode: http.StatusBadGateway}:   "unreachable",
+		errors.New("something else"):                          "error",
+	}
+	for err, want := range cases {
+		if got := image.KindName(fmt.Errorf("wrapped: %w", err)); got != want {
+			t.Errorf("KindName(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
+
+func TestLoader_ErrorKinds(t *testing.T) {
+	l := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) { return nil, errors.New("not in daemon") },
+		func(_ name.Reference) (v1.Image, error) {
+			return nil, &transport.Error{StatusCode: http.StatusServiceUnavailable}
+		},
+	)
+	if _, err := l.Load("alpine:latest", image.Auto); !errors.Is(err, image.ErrUnreachable) {
+		t.Errorf("got %v, want ErrUnreachable", err)
+	}
+	if _, err := l.Load("not a valid::ref", image.Auto); !errors.Is(err, image.ErrInvalidReference) {
+		t.Errorf("got %v, want ErrInvalidReference", err)
+	}
+}
+
+func TestLoader_RegistryNotFound(t *testing.T) {
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer srv.Close()
+
+	_, err := image.NewLoader().Load(strings.TrimPrefix(srv.URL, "http://")+"/missing:latest", image.RemoteOnly)
+	if !errors.Is(err, image.ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,129 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+)
+
+// refNameAnnotation is the OCI annotation that names a manifest in index.json.
+const refNameAnnotation = "org.opencontainers.image.ref.name"
+
+// defaultPlatform matches what remote.Image picks from an index when no
+// platform is requested, so layout and registry lookups agree.
+var defaultPlatform = v1.Platform{OS: "linux", Architecture: "amd64"}
+
+// fromLayout loads an image from an OCI image layout directory. ref has the
+// form path[:tag|@digest]; the tag is matched against the ref.name annotation.
+func (l *Loader) fromLayout(ref string) (*Resolved, error) {
+	dir, tag, digest := splitLayoutRef(ref)
+
+	p, err := layout.FromPath(dir)
+	if err != nil {
+		return nil, err
+	}
+	idx, err := p.ImageIndex()
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := idx.IndexManifest()
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []v1.Descriptor
+	for _, desc := range manifest.Manifests {
+		switch {
+		case digest != "":
+			if desc.Digest.String() == digest {
+				candidates = append(candidates, desc)
+			}
+		case tag != "":
+			name := desc.Annotations[refNameAnnotation]
+			if name == tag || strings.HasSuffix(name, ":"+tag) {
+				candidates = append(candidates, desc)
+			}
+		default:
+			candidates = append(candidates, desc)
+		}
+	}
+
+	desc, err := l.selectDescriptor(candidates)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+
+	if desc.MediaType.IsIndex() {
+		child, err := idx.ImageIndex(desc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		childManifest, err := child.IndexManifest()
+		if err != nil {
+			return nil, err
+		}
+		imgDesc, err := l.selectDescriptor(childManifest.Manifests)
+		if err != nil {
+			return nil, fmt.Errorf("%s: index %s: %w", dir, desc.Digest, err)
+		}
+		img, err := child.Image(imgDesc.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return &Resolved{Image: img, Index: child}, nil
+	}
+
+	img, err := idx.Image(desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	return &Resolved{Image: img}, nil
+}
+
+// selectDescriptor picks a single manifest from descs, narrowing by the
+// loader's platform (or the default platform) when there is more than one.
+func (l *Loader) selectDescriptor(descs []v1.Descriptor) (v1.Descriptor, error) {
+	if len(descs) == 0 {
+		return v1.Descriptor{}, &Error{Kind: ErrNotFound, Err: errors.New("no matching manifest")}
+	}
+
+	want := defaultPlatform
+	if l.platform != nil {
+		want = *l.platform
+	}
+	if len(descs) == 1 && (l.platform == nil || descs[0].Platform == nil) {
+		return descs[0], nil
+	}
+
+	var names []string
+	for _, desc := range descs {
+		if desc.Platform != nil && desc.Platform.Satisfies(want) {
+			return desc, nil
+		}
+		if name := desc.Annotations[refNameAnnotation]; name != "" {
+			names = append(names, name)
+		} else {
+			names = append(names, desc.Digest.String())
+		}
+	}
+	if len(descs) == 1 {
+		return v1.Descriptor{}, fmt.Errorf("manifest is not for platform %s", want)
+	}
+	return v1.Descriptor{}, fmt.Errorf("%d manifests match and none is for platform %s; choose one of: %s",
+		len(descs), want, strings.Join(names, ", "))
+}
+
+// splitLayoutRef splits "path:tag" or "path@sha256:..." into its parts. A colon
+// only separates a tag when it appears after the last path separator.
+func splitLayoutRef(ref string) (dir, tag, digest string) {
+	if i := strings.LastIndex(ref, "@"); i >= 0 {
+		return ref[:i], "", ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		return ref[:i], ref[i+1:], ""
+	}
+	return ref, "", ""
+}
@@ -0,0 +1,144 @@
+package image_test
+
+import (
+	"errors"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/layout"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+// writeLayout creates an OCI layout in a temp dir with one image per tag.
+func writeLayout(t *testing.T, tags map[string]v1.Image) string {
+	t.Helper()
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for tag, img := range tags {
+		err := p.AppendImage(img, layout.WithAnnotations(map[string]string{
+			"org.opencontainers.image.ref.name": tag,
+		}))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func digestOf(t *testing.T, img v1.Image) v1.Hash {
+	t.Helper()
+	h, err := img.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestLoader_OCILayout_ByTag(t *testing.T) {
+	v1Img, v2Img := randomImage(t), randomImage(t)
+	dir := writeLayout(t, map[string]v1.Image{"v1": v1Img, "v2": v2Img})
+	l := image.NewLoaderWithFetchers(nil, nil)
+
+	got, err := l.Load("oci:"+dir+":v2", image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestOf(t, got) != digestOf(t, v2Img) {
+		t.Error("expected image tagged v2")
+	}
+}
+
+func TestLoader_OCILayout_ByDigest(t *testing.T) {
+	v1Img, v2Img := randomImage(t), randomImage(t)
+	dir := writeLayout(t, map[string]v1.Image{"v1": v1Img, "v2": v2Img})
+	l := image.NewLoaderWithFetchers(nil, nil)
+
+	want := digestOf(t, v1Img)
+	got, err := l.Load("oci:"+dir+"@"+want.String(), image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestOf(t, got) != want {
+		t.Error("expected image with requested digest")
+	}
+}
+
+func TestLoader_OCILayout_SingleImageNeedsNoTag(t *testing.T) {
+	want := randomImage(t)
+	dir := writeLayout(t, map[string]v1.Image{"latest": want})
+	l := image.NewLoaderWithFetchers(nil, nil)
+
+	got, err := l.Load("oci:"+dir, image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if digestOf(t, got) != digestOf(t, want) {
+		t.Error("expected the only image in the layout")
+	}
+}
+
+func TestLoader_OCILayout_AmbiguousWithoutTag(t *testing.T) {
+	dir := writeLayout(t, map[string]v1.Image{"v1": randomImage(t), "v2": randomImage(t)})
+	l := image.NewLoaderWithFetchers(nil, nil)
+
+	if _, err := l.Load("oci:"+dir, image.Auto); err == nil {
+		t.Error("expected error when several images match")
+	}
+}
+
+func TestLoader_OCILayout_NestedIndex(t *testing.T) {
+	arm := randomImage(t)
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: randomImage(t), Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "linux", Architecture: "amd64"},
+		}},
+		mutate.IndexAddendum{Add: arm, Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "linux", Architecture: "arm64"},
+		}},
+	)
+	dir := t.TempDir()
+	p, err := layout.Write(dir, empty.Index)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AppendIndex(idx); err != nil {
+		t.Fatal(err)
+	}
+
+	l := image.NewLoaderWithFetchers(nil, nil)
+	l.SetPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})
+	res, err := l.Resolve("oci:"+dir, image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Index == nil {
+		t.Error("expected nested index to be reported")
+	}
+	if digestOf(t, res.Image) != digestOf(t, arm) {
+		t.Error("expected linux/arm64 child image")
+	}
+}
+
+func TestLoader_OCILayout_PlatformMismatch(t *testing.T) {
+	img := platformImage(t, v1.Platform{OS: "linux", Architecture: "amd64"})
+	dir := writeLayout(t, map[string]v1.Image{"latest": img})
+	l := image.NewLoaderWithFetchers(nil, nil)
+	l.SetPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})
+
+	_, err := l.Load("oci:"+dir, image.Auto)
+	if !errors.Is(err, image.ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound for a layout image with the wrong platform", err)
+	}
+}
+
+func TestLoader_OCILayout_RejectsRemoteOnly(t *testing.T) {
+	l := image.NewLoaderWithFetchers(nil, nil)
+	if _, err := l.Load("oci:./out", image.RemoteOnly); err == nil {
+		t.Error("expected error combining an oci: reference with RemoteOnly")
+	}
+}
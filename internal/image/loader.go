@@ -1,13 +1,21 @@
 package image
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
 
 	"github.com/google/go-containerregistry/pkg/authn"
 	"github.com/google/go-containerregistry/pkg/name"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
 	"github.com/google/go-containerregistry/pkg/v1/daemon"
+	"github.com/google/go-containerregistry/pkg/v1/partial"
 	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/thisisnotashwin/imgutil/internal/cache"
 )
 
 // Source controls where the loader looks for images.
@@ -17,25 +25,88 @@ const (
 	Auto       Source = iota // try daemon first, fall back to registry
 	LocalOnly                // daemon only
 	RemoteOnly               // registry only
+	OCILayout                // OCI image layout directory
+	Archive                  // `docker save` tarball
 )
 
+// schemes maps reference prefixes to the source they select, e.g.
+// "oci:./build/out:v1", "oci:./build/out@sha256:..." or "docker-archive:app.tar:app:v1".
+var schemes = []struct {
+	prefix string
+	src    Source
+}{
+	{"oci:", OCILayout},
+	{"docker-archive:", Archive},
+	{"tarball:", Archive},
+}
+
+// Resolved is an image together with how its reference was resolved.
+type Resolved struct {
+	Image v1.Image
+	// Index is set when the reference named a multi-platform index and Image
+	// is the child manifest selected for the loader's platform.
+	Index v1.ImageIndex
+}
+
 // Loader resolves Docker image references to v1.Image values.
 type Loader struct {
 	fromDaemon   func(name.Reference) (v1.Image, error)
-	fromRegistry func(name.Reference) (v1.Image, error)
+	fromRegistry func(name.Reference) (*Resolved, error)
+	fromIndex    func(name.Reference) (v1.ImageIndex, error)
+	// fromRegistryHead and fromDaemonInfo back Describe, which must not
+	// download the image.
+	fromRegistryHead func(name.Reference) (*v1.Descriptor, error)
+	fromDaemonInfo   func(name.Reference) (*daemonInfo, error)
+	listTags         func(name.Repository) ([]string, error)
+	catalog          func(reg name.Registry, last string, n int) ([]string, error)
+	platform         *v1.Platform
+	cache            *cache.Cache
+	logger           *log.Logger
+	debug            bool
 }
 
 // NewLoader returns a Loader backed by the local Docker daemon and the default
 // remote registry keychain (~/.docker/config.json).
 func NewLoader() *Loader {
-	return &Loader{
-		fromDaemon: func(ref name.Reference) (v1.Image, error) {
-			return daemon.Image(ref)
-		},
-		fromRegistry: func(ref name.Reference) (v1.Image, error) {
-			return remote.Image(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain))
-		},
+	l := &Loader{logger: discardLogger()}
+	l.fromDaemon = func(ref name.Reference) (v1.Image, error) {
+		return daemon.Image(ref)
 	}
+	l.fromRegistry = func(ref name.Reference) (*Resolved, error) {
+		desc, err := remote.Get(l.pinForCache(ref), l.remoteOptions()...)
+		if err != nil {
+			return nil, err
+		}
+		img, err := desc.Image()
+		if err != nil {
+			return nil, err
+		}
+		if l.cache != nil {
+			img = l.cache.Image(img)
+		}
+		res := &Resolved{Image: img}
+		if desc.MediaType.IsIndex() {
+			if res.Index, err = desc.ImageIndex(); err != nil {
+				return nil, err
+			}
+		}
+		return res, nil
+	}
+	l.fromIndex = func(ref name.Reference) (v1.ImageIndex, error) {
+		return remote.Index(ref, l.remoteOptions()...)
+	}
+	l.fromRegistryHead = l.headRegistry
+	l.fromDaemonInfo = inspectDaemon
+	l.listTags = func(repo name.Repository) ([]string, error) {
+		return remote.List(repo, l.remoteOptions()...)
+	}
+	l.catalog = func(reg name.Registry, last string, n int) ([]string, error) {
+		if n > 0 {
+			return remote.CatalogPage(reg, last, n, l.remoteOptions()...)
+		}
+		return remote.Catalog(context.Background(), reg, l.remoteOptions()...)
+	}
+	return l
 }
 
 // NewLoaderWithFetchers constructs a Loader with injected fetchers, for testing.
@@ -43,40 +114,278 @@ func NewLoaderWithFetchers(
 	fromDaemon func(name.Reference) (v1.Image, error),
 	fromRegistry func(name.Reference) (v1.Image, error),
 ) *Loader {
-	return &Loader{fromDaemon: fromDaemon, fromRegistry: fromRegistry}
+	return &Loader{
+		logger:     discardLogger(),
+		fromDaemon: fromDaemon,
+		fromRegistry: func(ref name.Reference) (*Resolved, error) {
+			img, err := fromRegistry(ref)
+			if err != nil {
+				return nil, err
+			}
+			return &Resolved{Image: img}, nil
+		},
+		fromRegistryHead: func(ref name.Reference) (*v1.Descriptor, error) {
+			img, err := fromRegistry(ref)
+			if err != nil {
+				return nil, err
+			}
+			return partial.Descriptor(img)
+		},
+		fromDaemonInfo: func(ref name.Reference) (*daemonInfo, error) {
+			img, err := fromDaemon(ref)
+			if err != nil {
+				return nil, err
+			}
+			id, err := img.ConfigName()
+			if err != nil {
+				return nil, err
+			}
+			cfg, err := img.ConfigFile()
+			if err != nil {
+				return nil, err
+			}
+			return &daemonInfo{ID: id.String(), Platform: cfg.Platform()}, nil
+		},
+	}
+}
+
+// SetPlatform restricts loading to images matching p. Multi-platform indexes
+// resolve to the matching child manifest, and single-platform images for
+// another platform, from any source, are treated as not found. A nil p
+// restores the default behaviour.
+func (l *Loader) SetPlatform(p *v1.Platform) {
+	l.platform = p
+}
+
+// SetDebug logs each loader decision and every registry request to w.
+func (l *Loader) SetDebug(w io.Writer) {
+	l.logger = log.New(w, "debug: ", log.LstdFlags)
+	l.debug = true
+}
+
+// SetCache keeps registry manifests, configs and layers in c so later loads
+// need not download them again. A nil c disables caching.
+func (l *Loader) SetCache(c *cache.Cache) {
+	l.cache = c
+}
+
+func discardLogger() *log.Logger {
+	return log.New(io.Discard, "", 0)
+}
+
+func (l *Loader) remoteOptions() []remote.Option {
+	opts := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain)}
+	if l.platform != nil {
+		opts = append(opts, remote.WithPlatform(*l.platform))
+	}
+	var t http.RoundTripper = remote.DefaultTransport
+	if l.debug {
+		t = newDebugTransport(t, l.logger)
+	}
+	if l.cache != nil {
+		t = l.cache.Transport(t)
+	}
+	if t != remote.DefaultTransport {
+		opts = append(opts, remote.WithTransport(t))
+	}
+	return opts
+}
+
+// pinForCache resolves a tag to its current digest with a HEAD request when
+// caching, so the manifest can then be served from the cache. On any failure
+// ref is returned unchanged.
+func (l *Loader) pinForCache(ref name.Reference) name.Reference {
+	if l.cache == nil {
+		return ref
+	}
+	if _, ok := ref.(name.Digest); ok {
+		return ref
+	}
+	desc, err := remote.Head(ref, l.remoteOptions()...)
+	if err != nil {
+		l.logger.Printf("cache: resolving %s: %v", ref, err)
+		return ref
+	}
+	return ref.Context().Digest(desc.Digest.String())
 }
 
 // Load resolves rawRef to a v1.Image using the given source strategy.
 func (l *Loader) Load(rawRef string, src Source) (v1.Image, error) {
+	res, err := l.Resolve(rawRef, src)
+	if err != nil {
+		return nil, err
+	}
+	return res.Image, nil
+}
+
+// Resolve is like Load but also reports whether the reference named an index.
+// References with a scheme prefix (see schemes) are routed to the
+// matching source; combining one with LocalOnly or RemoteOnly is an error.
+func (l *Loader) Resolve(rawRef string, src Source) (*Resolved, error) {
+	src, rest, err := sourceForRef(rawRef, src)
+	if err != nil {
+		return nil, err
+	}
+
+	switch src {
+	case OCILayout:
+		l.logger.Printf("loading %s from OCI layout", rest)
+		res, err := l.fromLayout(rest)
+		if err == nil {
+			err = l.checkResolved(res)
+		}
+		if err != nil {
+			return nil, Classify(fmt.Errorf("image %q not found in OCI layout: %w", rawRef, err))
+		}
+		return res, nil
+
+	case Archive:
+		l.logger.Printf("loading %s from archive", rest)
+		res, err := l.fromArchive(rest)
+		if err == nil {
+			err = l.checkResolved(res)
+		}
+		if err != nil {
+			return nil, Classify(fmt.Errorf("image %q not found in archive: %w", rawRef, err))
+		}
+		return res, nil
+	}
+
 	ref, err := name.ParseReference(rawRef)
 	if err != nil {
-		return nil, fmt.Errorf("invalid image reference %q: %w", rawRef, err)
+		return nil, &Error{Kind: ErrInvalidReference, Err: fmt.Errorf("invalid image reference %q: %w", rawRef, err)}
 	}
 
 	switch src {
 	case LocalOnly:
-		img, err := l.fromDaemon(ref)
+		l.logger.Printf("loading %s from daemon (local only)", ref)
+		img, err := l.daemonImage(ref)
 		if err != nil {
-			return nil, fmt.Errorf("image %q not found in local daemon: %w", rawRef, err)
+			return nil, Classify(fmt.Errorf("image %q not found in local daemon: %w", rawRef, err))
 		}
-		return img, nil
+		return &Resolved{Image: img}, nil
 
 	case RemoteOnly:
-		img, err := l.fromRegistry(ref)
+		l.logger.Printf("loading %s from registry (remote only)", ref)
+		res, err := l.registryImage(ref)
 		if err != nil {
-			return nil, fmt.Errorf("image %q not found in remote registry: %w", rawRef, err)
+			return nil, Classify(fmt.Errorf("image %q not found in remote registry: %w", rawRef, err))
 		}
-		return img, nil
+		return res, nil
 
 	default: // Auto
-		img, err := l.fromDaemon(ref)
+		l.logger.Printf("trying daemon for %s", ref)
+		img, err := l.daemonImage(ref)
 		if err == nil {
-			return img, nil
+			l.logger.Printf("loaded %s from daemon", ref)
+			return &Resolved{Image: img}, nil
 		}
-		img, err = l.fromRegistry(ref)
+		l.logger.Printf("daemon: %v; falling back to registry", err)
+		res, err := l.registryImage(ref)
 		if err != nil {
-			return nil, fmt.Errorf("image %q not found locally or in remote registry: %w", rawRef, err)
+			return nil, Classify(fmt.Errorf("image %q not found locally or in remote registry: %w", rawRef, err))
+		}
+		return res, nil
+	}
+}
+
+// LoadIndex fetches rawRef from the registry as a multi-platform index.
+// It fails if the reference names a single-platform image or has a scheme
+// prefix, since indexes are only read from registries.
+func (l *Loader) LoadIndex(rawRef string) (v1.ImageIndex, error) {
+	for _, s := range schemes {
+		if strings.HasPrefix(rawRef, s.prefix) {
+			return nil, &Error{
+				Kind: ErrInvalidReference,
+				Err:  fmt.Errorf("index does not support %s references; indexes can only be read from a remote registry", s.prefix),
+			}
+		}
+	}
+	ref, err := name.ParseReference(rawRef)
+	if err != nil {
+		return nil, &Error{Kind: ErrInvalidReference, Err: fmt.Errorf("invalid image reference %q: %w", rawRef, err)}
+	}
+	if l.fromIndex == nil {
+		return nil, errors.New("index lookup is not supported by this loader")
+	}
+	l.logger.Printf("loading index %s from registry", ref)
+	idx, err := l.fromIndex(ref)
+	if err != nil {
+		return nil, Classify(fmt.Errorf("index %q not found in remote registry: %w", rawRef, err))
+	}
+	return idx, nil
+}
+
+// sourceForRef strips any scheme prefix from rawRef and reconciles it with src.
+func sourceForRef(rawRef string, src Source) (Source, string, error) {
+	for _, s := range schemes {
+		rest, ok := strings.CutPrefix(rawRef, s.prefix)
+		if !ok {
+			continue
 		}
-		return img, nil
+		if src != Auto && src != s.src {
+			return src, "", &Error{
+				Kind: ErrInvalidReference,
+				Err:  fmt.Errorf("reference %q cannot be combined with --local or --remote", rawRef),
+			}
+		}
+		return s.src, rest, nil
+	}
+	return src, rawRef, nil
+}
+
+// registryImage loads ref from the registry, logging how it was resolved.
+func (l *Loader) registryImage(ref name.Reference) (*Resolved, error) {
+	res, err := l.fromRegistry(ref)
+	if err != nil {
+		l.logger.Printf("registry: %v", err)
+		return nil, err
+	}
+	if err := l.checkResolved(res); err != nil {
+		l.logger.Printf("registry: %v", err)
+		return nil, err
+	}
+	if res.Index != nil {
+		l.logger.Printf("loaded %s from registry via multi-platform index", ref)
+	} else {
+		l.logger.Printf("loaded %s from registry", ref)
+	}
+	return res, nil
+}
+
+// daemonImage loads ref from the daemon, rejecting images for another platform.
+func (l *Loader) daemonImage(ref name.Reference) (v1.Image, error) {
+	img, err := l.fromDaemon(ref)
+	if err != nil {
+		return nil, err
+	}
+	if err := l.checkPlatform(img); err != nil {
+		return nil, err
+	}
+	return img, nil
+}
+
+// checkResolved rejects a resolved image for another platform. Images picked
+// from an index already matched the platform in their descriptor.
+func (l *Loader) checkResolved(res *Resolved) error {
+	if res.Index != nil {
+		return nil
+	}
+	return l.checkPlatform(res.Image)
+}
+
+// checkPlatform reports an image whose config is for a platform other than
+// the loader's as not found.
+func (l *Loader) checkPlatform(img v1.Image) error {
+	if l.platform == nil {
+		return nil
+	}
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		return fmt.Errorf("reading config: %w", err)
+	}
+	if p := cfg.Platform(); p == nil || !p.Satisfies(*l.platform) {
+		return &Error{Kind: ErrNotFound, Err: fmt.Errorf("image is %s/%s, not %s", cfg.OS, cfg.Architecture, l.platform)}
 	}
+	return nil
 }
@@ -1,12 +1,21 @@
 package image_test
 
 import (
+	"bytes"
 	"errors"
+	"io"
+	"log"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
 	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
 	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
 	"github.com/thisisnotashwin/imgutil/internal/image"
 )
 
@@ -19,6 +28,23 @@ func randomImage(t *testing.T) v1.Image {
 	return img
 }
 
+// platformImage returns a random image whose config is for p.
+func platformImage(t *testing.T, p v1.Platform) v1.Image {
+	t.Helper()
+	img := randomImage(t)
+	cfg, err := img.ConfigFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg = cfg.DeepCopy()
+	cfg.OS, cfg.Architecture, cfg.Variant = p.OS, p.Architecture, p.Variant
+	img, err = mutate.ConfigFile(img, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return img
+}
+
 func TestLoader_LocalOnly(t *testing.T) {
 	want := randomImage(t)
 	l := image.NewLoaderWithFetchers(
@@ -101,3 +127,143 @@ func TestLoader_InvalidRef(t *testing.T) {
 		t.Error("expected error for invalid image reference")
 	}
 }
+
+func TestLoader_Platform_SelectsIndexChild(t *testing.T) {
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer srv.Close()
+	rawRef := strings.TrimPrefix(srv.URL, "http://") + "/app:multi"
+
+	arm := randomImage(t)
+	idx := mutate.AppendManifests(empty.Index,
+		mutate.IndexAddendum{Add: randomImage(t), Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "linux", Architecture: "amd64"},
+		}},
+		mutate.IndexAddendum{Add: arm, Descriptor: v1.Descriptor{
+			Platform: &v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+		}},
+	)
+	ref, err := name.ParseReference(rawRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.WriteIndex(ref, idx); err != nil {
+		t.Fatal(err)
+	}
+
+	l := image.NewLoader()
+	l.SetPlatform(&v1.Platform{OS: "linux", Architecture: "arm", Variant: "v7"})
+	res, err := l.Resolve(rawRef, image.RemoteOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Index == nil {
+		t.Error("expected index to be reported")
+	}
+	got, err := res.Image.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := arm.Digest()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("got image %s, want linux/arm/v7 child %s", got, want)
+	}
+
+	if _, err := l.LoadIndex(rawRef); err != nil {
+		t.Errorf("LoadIndex: %v", err)
+	}
+}
+
+func TestLoader_Platform_DaemonMismatchFallsBack(t *testing.T) {
+	daemonImg := randomImage(t) // random images have no platform in their config
+	want := platformImage(t, v1.Platform{OS: "linux", Architecture: "arm64"})
+	l := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) { return daemonImg, nil },
+		func(_ name.Reference) (v1.Image, error) { return want, nil },
+	)
+	l.SetPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})
+
+	got, err := l.Load("alpine:latest", image.Auto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Error("expected fallback to registry when daemon image has the wrong platform")
+	}
+	if _, err := l.Load("alpine:latest", image.LocalOnly); err == nil {
+		t.Error("expected error for daemon image with the wrong platform")
+	}
+}
+
+func TestLoader_Platform_RegistryMismatch(t *testing.T) {
+	img := platformImage(t, v1.Platform{OS: "linux", Architecture: "amd64"})
+	l := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) { return nil, errors.New("no daemon") },
+		func(_ name.Reference) (v1.Image, error) { return img, nil },
+	)
+	l.SetPlatform(&v1.Platform{OS: "linux", Architecture: "arm64"})
+
+	_, err := l.Load("alpine:latest", image.RemoteOnly)
+	if !errors.Is(err, image.ErrNotFound) {
+		t.Errorf("got %v, want ErrNotFound for a registry image with the wrong platform", err)
+	}
+
+	l.SetPlatform(&v1.Platform{OS: "linux", Architecture: "amd64"})
+	if _, err := l.Load("alpine:latest", image.RemoteOnly); err != nil {
+		t.Errorf("matching platform: %v", err)
+	}
+}
+
+func TestLoader_LoadIndex_Unsupported(t *testing.T) {
+	l := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) { return nil, nil },
+		func(_ name.Reference) (v1.Image, error) { return nil, nil },
+	)
+	if _, err := l.LoadIndex("alpine:latest"); err == nil {
+		t.Error("expected error when loader has no index fetcher")
+	}
+}
+
+func TestLoader_Debug_LogsRequests(t *testing.T) {
+	srv := httptest.NewServer(registry.New(registry.Logger(log.New(io.Discard, "", 0))))
+	defer srv.Close()
+	rawRef := strings.TrimPrefix(srv.URL, "http://") + "/app:latest"
+	ref, err := name.ParseReference(rawRef)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := remote.Write(ref, randomImage(t)); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	l := image.NewLoader()
+	l.SetDebug(&buf)
+	if _, err := l.Load(rawRef, image.RemoteOnly); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, "GET "+srv.URL+"/v2/app/manifests/latest: 200 OK") {
+		t.Errorf("debug log missing manifest request\ngot: %s", out)
+	}
+	if !strings.Contains(out, "loaded "+rawRef+" from registry") {
+		t.Errorf("debug log missing loader decision\ngot: %s", out)
+	}
+}
+
+func TestLoader_Debug_LogsFallback(t *testing.T) {
+	var buf bytes.Buffer
+	l := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) { return nil, io.ErrUnexpectedEOF },
+		func(_ name.Reference) (v1.Image, error) { return randomImage(t), nil },
+	)
+	l.SetDebug(&buf)
+	if _, err := l.Load("alpine:latest", image.Auto); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "falling back to registry") {
+		t.Errorf("debug log missing fallback\ngot: %s", buf.String())
+	}
+}
@@ -0,0 +1,45 @@
+package image
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/name"
+)
+
+// ListTags returns every tag in the repository rawRepo, such as
+// "ghcr.io/org/app", in the order the registry reports them.
+func (l *Loader) ListTags(rawRepo string) ([]string, error) {
+	repo, err := name.NewRepository(rawRepo)
+	if err != nil {
+		return nil, &Error{Kind: ErrInvalidReference, Err: fmt.Errorf("invalid repository %q: %w", rawRepo, err)}
+	}
+	if l.listTags == nil {
+		return nil, errors.New("tag listing is not supported by this loader")
+	}
+	l.logger.Printf("listing tags of %s", repo)
+	tags, err := l.listTags(repo)
+	if err != nil {
+		return nil, Classify(fmt.Errorf("listing tags of %q: %w", rawRepo, err))
+	}
+	return tags, nil
+}
+
+// Catalog returns the repositories on the registry rawRegistry. With n > 0 it
+// returns a single page of at most n repositories after last; otherwise it
+// follows the registry's pagination and returns them all.
+func (l *Loader) Catalog(rawRegistry, last string, n int) ([]string, error) {
+	reg, err := name.NewRegistry(rawRegistry)
+	if err != nil {
+		return nil, &Error{Kind: ErrInvalidReference, Err: fmt.Errorf("invalid registry %q: %w", rawRegistry, err)}
+	}
+	if l.catalog == nil {
+		return nil, errors.New("catalog listing is not supported by this loader")
+	}
+	l.logger.Printf("listing repositories of %s", reg)
+	repos, err := l.catalog(reg, last, n)
+	if err != nil {
+		return nil, Classify(fmt.Errorf("listing repositories of %q: %w", rawRegistry, err))
+	}
+	return repos, nil
+}
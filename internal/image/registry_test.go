@@ -0,0 +1,30 @@
+package image_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/image"
+)
+
+func TestLoader_ListTags_InvalidRepository(t *testing.T) {
+	_, err := image.NewLoader().ListTags("Not/A/Valid:Repo")
+	if !errors.Is(err, image.ErrInvalidReference) {
+		t.Errorf("got %v, want ErrInvalidReference", err)
+	}
+}
+
+func TestLoader_Catalog_Unsupported(t *testing.T) {
+	l := image.NewLoaderWithFetchers(
+		func(_ name.Reference) (v1.Image, error) { return nil, nil },
+		func(_ name.Reference) (v1.Image, error) { return nil, nil },
+	)
+	if _, err := l.Catalog("registry.example.com", "", 0); err == nil {
+		t.Error("expected error when loader has no catalog fetcher")
+	}
+	if _, err := l.ListTags("registry.example.com/app"); err == nil {
+		t.Error("expected error when loader has no tag lister")
+	}
+}
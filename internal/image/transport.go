@@ -0,0 +1,86 @@
+package image
+
+import (
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// redactedHeaders are never written to the debug log, nor are headers whose
+// name mentions a token, such as X-Auth-Token or X-Amz-Security-Token.
+var redactedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"X-Registry-Auth":     true,
+}
+
+func redactHeader(k string) bool {
+	k = http.CanonicalHeaderKey(k)
+	return redactedHeaders[k] || strings.Contains(k, "Token")
+}
+
+// redactURL returns u for logging, without its password or the values of
+// query parameters carrying credentials, like X-Amz-Signature and
+// X-Amz-Security-Token in the presigned storage URLs that registries
+// redirect blob downloads to.
+func redactURL(u *url.URL) string {
+	q := u.Query()
+	redacted := false
+	for k := range q {
+		lk := strings.ToLower(k)
+		for _, word := range []string{"sig", "credential", "token", "key"} {
+			if strings.Contains(lk, word) {
+				q[k] = []string{"<redacted>"}
+				redacted = true
+				break
+			}
+		}
+	}
+	if !redacted {
+		return u.Redacted()
+	}
+	c := *u
+	c.RawQuery = q.Encode()
+	return c.Redacted()
+}
+
+// debugTransport logs each registry request's method, URL, headers, status
+// and duration. Credentials are redacted.
+type debugTransport struct {
+	inner  http.RoundTripper
+	logger *log.Logger
+}
+
+func newDebugTransport(inner http.RoundTripper, logger *log.Logger) http.RoundTripper {
+	return &debugTransport{inner: inner, logger: logger}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.inner.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+
+	if err != nil {
+		t.logger.Printf("%s %s: %v (%s)", req.Method, redactURL(req.URL), err, elapsed)
+	} else {
+		t.logger.Printf("%s %s: %s (%s)", req.Method, redactURL(req.URL), resp.Status, elapsed)
+	}
+
+	keys := make([]string, 0, len(req.Header))
+	for k := range req.Header {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := req.Header.Get(k)
+		if redactHeader(k) {
+			v = "<redacted>"
+		}
+		t.logger.Printf("  %s: %s", k, v)
+	}
+	return resp, err
+}
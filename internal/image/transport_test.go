@@ -0,0 +1,73 @@
+package image
+
+import (
+	"bytes"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDebugTransport_RedactsCredentials(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	client := &http.Client{Transport: newDebugTransport(http.DefaultTransport, log.New(&buf, "", 0))}
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/v2/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range []string{"Authorization", "Proxy-Authorization", "Cookie", "X-Registry-Auth", "X-Auth-Token", "X-Amz-Security-Token"} {
+		req.Header.Set(h, "hunter2")
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	out := buf.String()
+	if strings.Contains(out, "hunter2") {
+		t.Errorf("debug log leaked credentials\ngot: %s", out)
+	}
+	for _, h := range []string{"Authorization", "Cookie", "X-Registry-Auth", "X-Auth-Token", "X-Amz-Security-Token"} {
+		if !strings.Contains(out, h+": <redacted>") {
+			t.Errorf("debug log missing redacted %s header\ngot: %s", h, out)
+		}
+	}
+	if !strings.Contains(out, "Accept: application/json") {
+		t.Errorf("debug log redacted an ordinary header\ngot: %s", out)
+	}
+	if !strings.Contains(out, "GET "+srv.URL+"/v2/: 418 I'm a teapot") {
+		t.Errorf("debug log missing request line\ngot: %s", out)
+	}
+}
+
+func TestDebugTransport_RedactsPresignedURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {}))
+	defer srv.Close()
+
+	var buf bytes.Buffer
+	client := &http.Client{Transport: newDebugTransport(http.DefaultTransport, log.New(&buf, "", 0))}
+	resp, err := client.Get(srv.URL + "/blobs/sha256:abc?X-Amz-Credential=AKIAEXAMPLE%2F20240101&X-Amz-Expires=300" +
+		"&X-Amz-Security-Token=hunter2&X-Amz-Signature=deadbeef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	out := buf.String()
+	for _, secret := range []string{"AKIAEXAMPLE", "hunter2", "deadbeef"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("debug log leaked %s\ngot: %s", secret, out)
+		}
+	}
+	if !strings.Contains(out, "X-Amz-Expires=300") {
+		t.Errorf("debug log redacted an ordinary query parameter\ngot: %s", out)
+	}
+}
@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"archive/tar"
+	"io"
+)
+
+// apkInstalled lists Alpine's installed packages; apk-tools 3 moved it under
+// /usr.
+var apkInstalled = map[string]bool{
+	"/lib/apk/db/installed":     true,
+	"/usr/lib/apk/db/installed": true,
+}
+
+func isApkInstalled(hdr *tar.Header) bool { return apkInstalled[hdr.Name] }
+
+// parseApkInstalled reads the packages in an apk installed database, whose
+// records use single-letter keys: P name, V version, A arch and o origin.
+func parseApkInstalled(p string, r io.Reader) ([]Package, error) {
+	var pkgs []Package
+	err := fields(r, ":", func(f map[string]string) error {
+		if f["P"] == "" {
+			return nil
+		}
+		source := f["o"]
+		if source == "" {
+			source = f["P"]
+		}
+		pkgs = append(pkgs, Package{
+			Type:    "apk",
+			Name:    f["P"],
+			Version: f["V"],
+			Arch:    f["A"],
+			Source:  source,
+			Path:    p,
+		})
+		return nil
+	})
+	return pkgs, err
+}
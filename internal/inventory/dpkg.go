@@ -0,0 +1,52 @@
+package inventory
+
+import (
+	"archive/tar"
+	"io"
+	"path"
+	"strings"
+)
+
+// Debian records installed packages in a single status file; distroless
+// images instead ship one file per package under status.d.
+const (
+	dpkgStatus  = "/var/lib/dpkg/status"
+	dpkgStatusD = "/var/lib/dpkg/status.d"
+)
+
+func isDpkgStatus(hdr *tar.Header) bool {
+	p := hdr.Name
+	if p == dpkgStatus {
+		return true
+	}
+	return path.Dir(p) == dpkgStatusD && !strings.HasSuffix(p, ".md5sums")
+}
+
+// parseDpkgStatus reads the packages in a dpkg status file, skipping those
+// that are removed or only have configuration files left.
+func parseDpkgStatus(p string, r io.Reader) ([]Package, error) {
+	var pkgs []Package
+	err := fields(r, ":", func(f map[string]string) error {
+		if f["Package"] == "" {
+			return nil
+		}
+		// status.d entries carry no Status field.
+		if s, ok := f["Status"]; ok && !strings.HasSuffix(s, " installed") {
+			return nil
+		}
+		source, _, _ := strings.Cut(f["Source"], " ")
+		if source == "" {
+			source = f["Package"]
+		}
+		pkgs = append(pkgs, Package{
+			Type:    "deb",
+			Name:    f["Package"],
+			Version: f["Version"],
+			Arch:    f["Architecture"],
+			Source:  source,
+			Path:    p,
+		})
+		return nil
+	})
+	return pkgs, err
+}
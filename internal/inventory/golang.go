@@ -0,0 +1,58 @@
+package inventory
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"debug/buildinfo"
+	"io"
+	"strings"
+)
+
+var elfMagic = []byte("\x7fELF")
+
+func isExecutable(hdr *tar.Header) bool {
+	return hdr.Mode&0o111 != 0 && hdr.Size > 0 && hdr.Size <= maxReadSize
+}
+
+// parseGoBinary reads the build info Go embeds in ELF binaries: the main
+// module, every dependency compiled in (after replacements) and the
+// standard library, reported as "stdlib" at the toolchain's version. Other
+// executables yield nothing.
+func parseGoBinary(p string, r io.Reader) ([]Package, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(elfMagic)); err != nil || !bytes.Equal(magic, elfMagic) {
+		return nil, nil
+	}
+	ra, _, release, err := spool(br)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	info, err := buildinfo.Read(ra)
+	if err != nil {
+		return nil, nil
+	}
+
+	var arch string
+	for _, s := range info.Settings {
+		if s.Key == "GOARCH" {
+			arch = s.Value
+		}
+	}
+	pkg := func(name, version string) Package {
+		return Package{Type: "go", Name: name, Version: version, Arch: arch, Path: p}
+	}
+
+	pkgs := []Package{pkg("stdlib", strings.TrimPrefix(info.GoVersion, "go"))}
+	if info.Main.Path != "" {
+		pkgs = append(pkgs, pkg(info.Main.Path, info.Main.Version))
+	}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		pkgs = append(pkgs, pkg(dep.Path, dep.Version))
+	}
+	return pkgs, nil
+}
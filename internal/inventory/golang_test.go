@@ -0,0 +1,80 @@
+package inventory_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"debug/buildinfo"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+)
+
+func TestScan_GoBinary(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("needs an ELF test binary")
+	}
+	// The test binary is itself a Go binary with embedded build info.
+	exe, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin, err := os.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := buildinfo.ReadFile(exe)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, f := range []struct {
+		name string
+		mode int64
+		body []byte
+	}{
+		{"usr/local/bin/app", 0o755, bin},
+		{"usr/local/bin/script", 0o755, []byte("#!/bin/sh\necho hi\n")},
+		{"opt/app.data", 0o644, bin},
+	} {
+		if err := tw.WriteHeader(&tar.Header{Name: f.name, Mode: f.mode, Typeflag: tar.TypeReg, Size: int64(len(f.body))}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write(f.body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(b)), nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	inv, err := inventory.Scan([]v1.Layer{l})
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]inventory.Package{}
+	for _, p := range inv.Packages {
+		if p.Type != "go" || p.Path != "/usr/local/bin/app" {
+			t.Errorf("unexpected package %+v", p)
+		}
+		byName[p.Name] = p
+	}
+	if got := byName["stdlib"].Version; got != strings.TrimPrefix(info.GoVersion, "go") {
+		t.Errorf("got stdlib %q, want %s", got, info.GoVersion)
+	}
+	if _, ok := byName["github.com/google/go-containerregistry"]; !ok {
+		t.Errorf("missing dependency go-containerregistry in %v", byName)
+	}
+}
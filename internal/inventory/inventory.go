@@ -0,0 +1,211 @@
+// Package inventory lists the packages installed in an image: OS packages
+// from the package databases left in its filesystem, and language-level
+// dependencies from Go binaries, Python distributions, node_modules and JARs.
+package inventory
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+// Package is one installed package.
+type Package struct {
+	Type    string // "deb", "apk", "rpm", "go", "python", "npm" or "java"
+	Name    string
+	Version string
+	Arch    string
+	Source  string // source package the binary package was built from
+	Path    string // file that records the package
+	Layer   int    // index of the layer that installed this version
+}
+
+// Types lists every package type, OS package managers first.
+var Types = []string{"deb", "apk", "rpm", "go", "python", "npm", "java"}
+
+// detector recognises a file that records packages and parses them. OS
+// package databases must parse; language files that fail to parse are
+// skipped, since images commonly carry malformed or partial ones.
+type detector struct {
+	match func(hdr *tar.Header) bool
+	parse func(p string, r io.Reader) ([]Package, error)
+}
+
+var detectors = []detector{
+	{match: isDpkgStatus, parse: parseDpkgStatus},
+	{match: isApkInstalled, parse: parseApkInstalled},
+	{match: isRpmDB, parse: parseRpmDB},
+	{match: isPythonMetadata, parse: parsePythonMetadata},
+	{match: isNodePackage, parse: parseNodePackage},
+	{match: isJar, parse: parseJar},
+	{match: isExecutable, parse: parseGoBinary},
+}
+
+// maxReadSize bounds the binaries and archives read. Those over
+// maxMemorySize are spooled to a temporary file rather than held in memory.
+const (
+	maxReadSize   = 512 << 20
+	maxMemorySize = 16 << 20
+)
+
+// Inventory is what Scan found in an image.
+type Inventory struct {
+	// OS identifies the distribution, or is nil if the image has no
+	// os-release file.
+	OS       *OSRelease
+	Packages []Package
+}
+
+// Scan streams every layer once and returns the packages installed in the
+// final filesystem, sorted by name. A package is attributed to the layer that
+// first recorded its current version; later rewrites of the database that
+// leave it unchanged do not move it.
+func Scan(layers []v1.Layer) (*Inventory, error) {
+	files := map[string][]Package{}
+	releases := map[string]*OSRelease{}
+	// What each entry parses to, recorded once Merge adds the entry.
+	parsed := map[*tar.Header][]Package{}
+	parsedReleases := map[*tar.Header]*OSRelease{}
+
+	err := layer.Merge(layers, layer.Merger{
+		Entry: func(_ int, hdr *tar.Header, r io.Reader) error {
+			if hdr.Typeflag != tar.TypeReg {
+				return nil
+			}
+			if osReleasePaths[hdr.Name] {
+				rel, err := parseOSRelease(r)
+				if err != nil {
+					return fmt.Errorf("parsing %s: %w", hdr.Name, err)
+				}
+				parsedReleases[hdr] = rel
+				return nil
+			}
+			for _, d := range detectors {
+				if !d.match(hdr) {
+					continue
+				}
+				pkgs, err := d.parse(hdr.Name, r)
+				if err != nil {
+					return fmt.Errorf("parsing %s: %w", hdr.Name, err)
+				}
+				if len(pkgs) > 0 {
+					parsed[hdr] = pkgs
+				}
+				break
+			}
+			return nil
+		},
+		Remove: func(_ int, p string) {
+			delete(files, p)
+			delete(releases, p)
+		},
+		// Anything replacing a file that recorded packages drops them,
+		// unless it parses to packages of its own.
+		Add: func(i int, hdr *tar.Header) {
+			if pkgs, ok := parsed[hdr]; ok {
+				files[hdr.Name] = attribute(files[hdr.Name], pkgs, i)
+				delete(parsed, hdr)
+			} else {
+				delete(files, hdr.Name)
+			}
+			if rel, ok := parsedReleases[hdr]; ok {
+				releases[hdr.Name] = rel
+				delete(parsedReleases, hdr)
+			} else {
+				delete(releases, hdr.Name)
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Inventory{OS: releases["/etc/os-release"]}
+	if inv.OS == nil {
+		inv.OS = releases["/usr/lib/os-release"]
+	}
+	for _, pkgs := range files {
+		inv.Packages = append(inv.Packages, pkgs...)
+	}
+	sort.Slice(inv.Packages, func(i, j int) bool {
+		a, b := inv.Packages[i], inv.Packages[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		if a.Version != b.Version {
+			return a.Version < b.Version
+		}
+		return a.Arch < b.Arch
+	})
+	return inv, nil
+}
+
+// attribute sets the layer of each package in pkgs, the database as written
+// by layer i: packages whose version is unchanged since prev keep their
+// layer, and the rest were installed by layer i.
+func attribute(prev, pkgs []Package, i int) []Package {
+	before := map[string]Package{}
+	for _, p := range prev {
+		before[p.Name+"\x00"+p.Arch] = p
+	}
+	for j := range pkgs {
+		p := &pkgs[j]
+		p.Layer = i
+		if old, ok := before[p.Name+"\x00"+p.Arch]; ok && old.Version == p.Version {
+			p.Layer = old.Layer
+		}
+	}
+	return pkgs
+}
+
+// fields splits a Debian control-style paragraph stream into records. Lines
+// starting with whitespace continue the previous field; a blank line ends a
+// record. sep separates a field's name from its value.
+func fields(r io.Reader, sep string, record func(map[string]string) error) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	cur := map[string]string{}
+	var last string
+	flush := func() error {
+		if len(cur) == 0 {
+			return nil
+		}
+		err := record(cur)
+		cur, last = map[string]string{}, ""
+		return err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimRight(line, "\r")
+		switch {
+		case strings.TrimSpace(line) == "":
+			if err := flush(); err != nil {
+				return err
+			}
+		case line[0] == ' ' || line[0] == '\t':
+			if last != "" {
+				cur[last] += "\n" + strings.TrimSpace(line)
+			}
+		default:
+			k, v, ok := strings.Cut(line, sep)
+			if !ok {
+				continue
+			}
+			last = strings.TrimSpace(k)
+			cur[last] = strings.TrimSpace(v)
+		}
+	}
+	return flush()
+}
@@ -0,0 +1,204 @@
+package inventory_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"sort"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+)
+
+// tarLayer builds a layer from path → content.
+func tarLayer(t *testing.T, files map[string]string) v1.Layer {
+	t.Helper()
+	paths := make([]string, 0, len(files))
+	for p := range files {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, p := range paths {
+		hdr := &tar.Header{Name: p, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(files[p]))}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(files[p])); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func scan(t *testing.T, layers ...map[string]string) map[string]inventory.Package {
+	t.Helper()
+	ls := make([]v1.Layer, 0, len(layers))
+	for _, files := range layers {
+		ls = append(ls, tarLayer(t, files))
+	}
+	inv, err := inventory.Scan(ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := map[string]inventory.Package{}
+	for _, p := range inv.Packages {
+		byName[p.Name] = p
+	}
+	return byName
+}
+
+const (
+	libc = `Package: libc6
+Status: install ok installed
+Architecture: amd64
+Source: glibc (2.31-13)
+Version: 2.31-13
+Description: GNU C Library
+ Contains the standard libraries.
+`
+	baseFiles = `Package: base-files
+Status: install ok installed
+Architecture: amd64
+Version: 11.1
+`
+	curl = `Package: curl
+Status: install ok installed
+Architecture: amd64
+Version: 7.74.0-1.3
+`
+	removed = `Package: vim-tiny
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 2:8.2.2434-3
+`
+)
+
+func TestScan_DpkgAttributesInstallingLayer(t *testing.T) {
+	pkgs := scan(t,
+		map[string]string{"var/lib/dpkg/status": libc + "\n" + baseFiles + "\n" + removed},
+		map[string]string{"var/lib/dpkg/status": libc + "\n" + baseFiles + "\n" + curl},
+		map[string]string{"var/lib/dpkg/status": baseFiles + "\n" + curl + "\n" +
+			`Package: libc6
+Status: install ok installed
+Architecture: amd64
+Source: glibc (2.31-13+deb11u5)
+Version: 2.31-13+deb11u5
+`},
+	)
+
+	want := map[string]inventory.Package{
+		"base-files": {Type: "deb", Name: "base-files", Version: "11.1", Arch: "amd64", Source: "base-files", Path: "/var/lib/dpkg/status", Layer: 0},
+		"curl":       {Type: "deb", Name: "curl", Version: "7.74.0-1.3", Arch: "amd64", Source: "curl", Path: "/var/lib/dpkg/status", Layer: 1},
+		"libc6":      {Type: "deb", Name: "libc6", Version: "2.31-13+deb11u5", Arch: "amd64", Source: "glibc", Path: "/var/lib/dpkg/status", Layer: 2},
+	}
+	if len(pkgs) != len(want) {
+		t.Errorf("got %d packages, want %d: %v", len(pkgs), len(want), pkgs)
+	}
+	for name, w := range want {
+		if got := pkgs[name]; got != w {
+			t.Errorf("%s: got %+v, want %+v", name, got, w)
+		}
+	}
+}
+
+func TestScan_DistrolessStatusD(t *testing.T) {
+	pkgs := scan(t,
+		map[string]string{
+			"var/lib/dpkg/status.d/libc6":         "Package: libc6\nVersion: 2.31-13\nArchitecture: amd64\nSource: glibc\n",
+			"var/lib/dpkg/status.d/libc6.md5sums": "d41d8cd98f00b204e9800998ecf8427e  lib/libc.so.6\n",
+			"var/lib/dpkg/status.d/tzdata":        "Package: tzdata\nVersion: 2024a-0\nArchitecture: all\n",
+		},
+		map[string]string{"var/lib/dpkg/status.d/.wh.tzdata": ""},
+	)
+	if len(pkgs) != 1 || pkgs["libc6"].Source != "glibc" || pkgs["libc6"].Layer != 0 {
+		t.Errorf("got %+v, want only libc6 from layer 0", pkgs)
+	}
+}
+
+func TestScan_Apk(t *testing.T) {
+	pkgs := scan(t, map[string]string{"lib/apk/db/installed": `C:Q1abc=
+P:musl
+V:1.2.4-r2
+A:x86_64
+o:musl
+T:the musl c library
+
+P:busybox-binsh
+V:1.36.1-r5
+A:x86_64
+o:busybox
+`})
+	got := pkgs["busybox-binsh"]
+	if got.Type != "apk" || got.Version != "1.36.1-r5" || got.Arch != "x86_64" || got.Source != "busybox" {
+		t.Errorf("got %+v", got)
+	}
+	if _, ok := pkgs["musl"]; !ok || len(pkgs) != 2 {
+		t.Errorf("got %v, want musl and busybox-binsh", pkgs)
+	}
+}
+
+func TestScan_PythonAndNode(t *testing.T) {
+	pkgs := scan(t,
+		map[string]string{
+			"usr/lib/python3/site-packages/requests-2.31.0.dist-info/METADATA": "Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\n\nName: not-a-header\n",
+			"usr/lib/python3/site-packages/six.egg-info/PKG-INFO":              "Metadata-Version: 1.0\nName: six\nVersion: 1.16.0\n",
+			"app/node_modules/lodash/package.json":                             `{"name": "lodash", "version": "4.17.21"}`,
+			"app/node_modules/@babel/core/package.json":                        `{"name": "@babel/core", "version": "7.24.0"}`,
+			"app/node_modules/lodash/fp/package.json":                          `{"name": "lodash-fp-internal"}`,
+			"app/node_modules/broken/package.json":                             `{not json`,
+		},
+		map[string]string{"app/node_modules/lodash/package.json": `{"name": "lodash", "version": "4.17.21"}`},
+	)
+
+	want := map[string]inventory.Package{
+		"requests":    {Type: "python", Name: "requests", Version: "2.31.0", Path: "/usr/lib/python3/site-packages/requests-2.31.0.dist-info/METADATA"},
+		"six":         {Type: "python", Name: "six", Version: "1.16.0", Path: "/usr/lib/python3/site-packages/six.egg-info/PKG-INFO"},
+		"lodash":      {Type: "npm", Name: "lodash", Version: "4.17.21", Path: "/app/node_modules/lodash/package.json"},
+		"@babel/core": {Type: "npm", Name: "@babel/core", Version: "7.24.0", Path: "/app/node_modules/@babel/core/package.json"},
+	}
+	if len(pkgs) != len(want) {
+		t.Errorf("got %d packages, want %d: %v", len(pkgs), len(want), pkgs)
+	}
+	for name, w := range want {
+		if got := pkgs[name]; got != w {
+			t.Errorf("%s: got %+v, want %+v", name, got, w)
+		}
+	}
+}
+
+func TestScan_OSReleaseAndReplacedFiles(t *testing.T) {
+	ls := []v1.Layer{
+		tarLayer(t, map[string]string{
+			"usr/lib/os-release":              "PRETTY_NAME=\"Debian GNU/Linux 12 (bookworm)\"\nID=debian\nVERSION_ID=\"12\"\n",
+			"var/lib/dpkg/status":             baseFiles,
+			"app/node_modules/x/package.json": `{"name": "x", "version": "1.0.0"}`,
+		}),
+		// Overwriting a package file with one that records nothing drops it.
+		tarLayer(t, map[string]string{"app/node_modules/x/package.json": `{}`}),
+	}
+	inv, err := inventory.Scan(ls)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv.OS == nil || inv.OS.ID != "debian" || inv.OS.VersionID != "12" || inv.OS.PrettyName != "Debian GNU/Linux 12 (bookworm)" {
+		t.Errorf("got OS %+v", inv.OS)
+	}
+	if len(inv.Packages) != 1 || inv.Packages[0].Name != "base-files" {
+		t.Errorf("got %+v, want only base-files", inv.Packages)
+	}
+}
@@ -0,0 +1,164 @@
+package inventory
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"io"
+	"path"
+	"strings"
+)
+
+// maxJarDepth bounds how deeply JARs nested in JARs, as in Spring Boot's
+// BOOT-INF/lib, are opened.
+const maxJarDepth = 2
+
+var jarExts = map[string]bool{".jar": true, ".war": true, ".ear": true}
+
+func isJar(hdr *tar.Header) bool {
+	return jarExts[path.Ext(hdr.Name)] && hdr.Size <= maxReadSize
+}
+
+// maxMetadataSize bounds the manifests and pom.properties read from a JAR.
+const maxMetadataSize = 1 << 20
+
+func parseJar(p string, r io.Reader) ([]Package, error) {
+	ra, size, release, err := spool(r)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return jarPackages(p, ra, size, 0), nil
+}
+
+// jarPackages lists the Maven artifacts a JAR records in
+// META-INF/maven/**/pom.properties, falling back to the JAR's own manifest
+// when it has none, followed by the contents of nested JARs. Nested JARs are
+// reported with paths like app.jar!/BOOT-INF/lib/dep.jar; those larger than
+// maxReadSize once decompressed are skipped.
+func jarPackages(p string, ra io.ReaderAt, size int64, depth int) []Package {
+	zr, err := zip.NewReader(ra, size)
+	if err != nil {
+		return nil
+	}
+
+	var (
+		pkgs     []Package
+		manifest map[string]string
+		nested   []*zip.File
+	)
+	for _, f := range zr.File {
+		switch {
+		case strings.HasPrefix(f.Name, "META-INF/maven/") && path.Base(f.Name) == "pom.properties":
+			props := readZipEntry(f, properties)
+			if props["artifactId"] == "" {
+				continue
+			}
+			pkgs = append(pkgs, Package{
+				Type:    "java",
+				Name:    props["groupId"] + ":" + props["artifactId"],
+				Version: props["version"],
+				Path:    p,
+			})
+		case f.Name == "META-INF/MANIFEST.MF":
+			manifest = readZipEntry(f, manifestHeaders)
+		case jarExts[path.Ext(f.Name)] && depth < maxJarDepth:
+			nested = append(nested, f)
+		}
+	}
+
+	if len(pkgs) == 0 {
+		name := firstOf(manifest, "Implementation-Title", "Bundle-SymbolicName")
+		if name == "" {
+			name = strings.TrimSuffix(path.Base(p), path.Ext(p))
+		}
+		pkgs = append(pkgs, Package{
+			Type:    "java",
+			Name:    name,
+			Version: firstOf(manifest, "Implementation-Version", "Bundle-Version"),
+			Path:    p,
+		})
+	}
+
+	for _, f := range nested {
+		pkgs = append(pkgs, nestedJarPackages(p+"!/"+f.Name, f, depth+1)...)
+	}
+	return pkgs
+}
+
+// nestedJarPackages lists the packages in the JAR f within another. Its
+// declared size is not trusted: spool stops reading at maxReadSize.
+func nestedJarPackages(p string, f *zip.File, depth int) []Package {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = rc.Close() }()
+	ra, size, release, err := spool(rc)
+	if err != nil {
+		return nil
+	}
+	defer release()
+	return jarPackages(p, ra, size, depth)
+}
+
+func readZipEntry(f *zip.File, parse func(io.Reader) map[string]string) map[string]string {
+	rc, err := f.Open()
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = rc.Close() }()
+	return parse(io.LimitReader(rc, maxMetadataSize))
+}
+
+// properties parses a Java .properties file's key=value lines.
+func properties(r io.Reader) map[string]string {
+	props := map[string]string{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			props[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return props
+}
+
+// manifestHeaders parses the main section of a JAR manifest. Lines starting
+// with a single space continue the previous header.
+func manifestHeaders(r io.Reader) map[string]string {
+	headers := map[string]string{}
+	var last string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		switch {
+		case line == "":
+			return headers
+		case line[0] == ' ':
+			if last != "" {
+				headers[last] += line[1:]
+			}
+		default:
+			if k, v, ok := strings.Cut(line, ":"); ok {
+				last = strings.TrimSpace(k)
+				headers[last] = strings.TrimSpace(v)
+			}
+		}
+	}
+	return headers
+}
+
+func firstOf(m map[string]string, keys ...string) string {
+	for _, k := range keys {
+		if v := m[k]; v != "" {
+			// OSGi symbolic names may carry directives: "org.foo;singleton:=true".
+			v, _, _ = strings.Cut(v, ";")
+			return v
+		}
+	}
+	return ""
+}
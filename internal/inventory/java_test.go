@@ -0,0 +1,97 @@
+package inventory_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+)
+
+// jar builds a zip archive from name → content.
+func jar(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, body := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestScan_Jars(t *testing.T) {
+	nested := jar(t, map[string][]byte{
+		"META-INF/MANIFEST.MF": []byte("Manifest-Version: 1.0\r\nBundle-SymbolicName: org.yaml.snakeyaml;singleton:=true\r\nBundle-Version: 2.2\r\n\r\n"),
+	})
+	app := jar(t, map[string][]byte{
+		"META-INF/MANIFEST.MF":                                                  []byte("Manifest-Version: 1.0\nImplementation-Title: demo\n"),
+		"META-INF/maven/com.example/demo/pom.properties":                        []byte("#Generated\ngroupId=com.example\nartifactId=demo\nversion=1.0.0\n"),
+		"META-INF/maven/com.fasterxml.jackson.core/jackson-core/pom.properties": []byte("groupId=com.fasterxml.jackson.core\nartifactId=jackson-core\nversion=2.17.0\n"),
+		"BOOT-INF/lib/snakeyaml-2.2.jar":                                        nested,
+		"BOOT-INF/classes/com/example/Demo.class":                               []byte("\xca\xfe\xba\xbe"),
+	})
+
+	pkgs := scan(t, map[string]string{
+		"app/demo.jar":       string(app),
+		"app/lib/plain.jar":  string(jar(t, map[string][]byte{"a.class": nil})),
+		"app/not-a-real.jar": "garbage",
+	})
+
+	want := map[string]struct{ version, path string }{
+		"com.example:demo":                        {"1.0.0", "/app/demo.jar"},
+		"com.fasterxml.jackson.core:jackson-core": {"2.17.0", "/app/demo.jar"},
+		"org.yaml.snakeyaml":                      {"2.2", "/app/demo.jar!/BOOT-INF/lib/snakeyaml-2.2.jar"},
+		"plain":                                   {"", "/app/lib/plain.jar"},
+	}
+	if len(pkgs) != len(want) {
+		t.Errorf("got %d packages, want %d: %v", len(pkgs), len(want), pkgs)
+	}
+	for name, w := range want {
+		got, ok := pkgs[name]
+		if !ok || got.Type != "java" || got.Version != w.version || got.Path != w.path {
+			t.Errorf("%s: got %+v, want version %q at %s", name, got, w.version, w.path)
+		}
+	}
+}
+
+// storedJar builds an uncompressed zip archive holding files and size bytes
+// of padding, so it is as large on disk as in memory.
+func storedJar(t *testing.T, files map[string][]byte, size int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files["padding.bin"] = make([]byte, size)
+	for name, body := range files {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestScan_LargeJars(t *testing.T) {
+	// Both are over the 16 MiB held in memory, so they are spooled to disk.
+	nested := storedJar(t, map[string][]byte{
+		"META-INF/maven/org.example/lib/pom.properties": []byte("groupId=org.example\nartifactId=lib\nversion=3.1\n"),
+	}, 17<<20)
+	app := storedJar(t, map[string][]byte{"BOOT-INF/lib/lib-3.1.jar": nested}, 0)
+
+	pkgs := scan(t, map[string]string{"app/big.jar": string(app)})
+	if got := pkgs["org.example:lib"]; got.Version != "3.1" || got.Path != "/app/big.jar!/BOOT-INF/lib/lib-3.1.jar" {
+		t.Errorf("got %+v, want org.example:lib 3.1 in the nested JAR", got)
+	}
+}
@@ -0,0 +1,34 @@
+package inventory
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"path"
+	"strings"
+)
+
+// isNodePackage matches the package.json at the root of an installed
+// package: node_modules/<name>/package.json or
+// node_modules/@<scope>/<name>/package.json.
+func isNodePackage(hdr *tar.Header) bool {
+	if path.Base(hdr.Name) != "package.json" {
+		return false
+	}
+	parent := path.Dir(path.Dir(hdr.Name))
+	if strings.HasPrefix(path.Base(parent), "@") {
+		parent = path.Dir(parent)
+	}
+	return path.Base(parent) == "node_modules"
+}
+
+func parseNodePackage(p string, r io.Reader) ([]Package, error) {
+	var pkg struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(r).Decode(&pkg); err != nil || pkg.Name == "" {
+		return nil, nil
+	}
+	return []Package{{Type: "npm", Name: pkg.Name, Version: pkg.Version, Path: p}}, nil
+}
@@ -0,0 +1,44 @@
+package inventory
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// osReleasePaths are where os-release(5) may live. /etc/os-release is often a
+// symlink to the copy under /usr, so both are read.
+var osReleasePaths = map[string]bool{
+	"/etc/os-release":     true,
+	"/usr/lib/os-release": true,
+}
+
+// OSRelease identifies an image's distribution.
+type OSRelease struct {
+	ID         string // e.g. "debian", "alpine", "rhel"
+	VersionID  string // e.g. "12", "3.19.1"
+	PrettyName string
+}
+
+// parseOSRelease reads the KEY=value lines of an os-release file. Values may
+// be quoted.
+func parseOSRelease(r io.Reader) (*OSRelease, error) {
+	rel := &OSRelease{}
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		k, v, ok := strings.Cut(strings.TrimSpace(sc.Text()), "=")
+		if !ok || strings.HasPrefix(k, "#") {
+			continue
+		}
+		v = strings.Trim(v, `"'`)
+		switch k {
+		case "ID":
+			rel.ID = v
+		case "VERSION_ID":
+			rel.VersionID = v
+		case "PRETTY_NAME":
+			rel.PrettyName = v
+		}
+	}
+	return rel, sc.Err()
+}
@@ -0,0 +1,103 @@
+package inventory
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// PURL returns the package URL (https://github.com/package-url/purl-spec)
+// identifying p, or "" when p lacks the details its type requires. OS
+// packages are namespaced by the distribution in os, which may be nil.
+func (p Package) PURL(os *OSRelease) string {
+	var (
+		purlType  string
+		namespace string
+		name      = p.Name
+		version   = p.Version
+		quals     = map[string]string{}
+	)
+	distro := func(fallback string) string {
+		if os == nil || os.ID == "" {
+			return fallback
+		}
+		if os.VersionID != "" {
+			quals["distro"] = os.ID + "-" + os.VersionID
+		}
+		return os.ID
+	}
+
+	switch p.Type {
+	case "deb":
+		purlType, namespace = "deb", distro("debian")
+		quals["arch"] = p.Arch
+	case "apk":
+		purlType, namespace = "apk", distro("alpine")
+		quals["arch"] = p.Arch
+	case "rpm":
+		purlType, namespace = "rpm", distro("")
+		quals["arch"] = p.Arch
+		if epoch, rest, ok := strings.Cut(version, ":"); ok {
+			quals["epoch"], version = epoch, rest
+		}
+	case "go":
+		purlType = "golang"
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			namespace, name = name[:i], name[i+1:]
+		}
+	case "python":
+		// PyPI names are case-insensitive with "_" equivalent to "-".
+		purlType, name = "pypi", strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+	case "npm":
+		purlType = "npm"
+		if scope, rest, ok := strings.Cut(name, "/"); ok {
+			namespace, name = scope, rest
+		}
+	case "java":
+		purlType = "maven"
+		var ok bool
+		if namespace, name, ok = strings.Cut(name, ":"); !ok {
+			return ""
+		}
+	default:
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString("pkg:" + purlType + "/")
+	if namespace != "" {
+		segs := strings.Split(namespace, "/")
+		for i, s := range segs {
+			segs[i] = purlEscape(s)
+		}
+		b.WriteString(strings.Join(segs, "/") + "/")
+	}
+	b.WriteString(purlEscape(name))
+	if version != "" {
+		b.WriteString("@" + purlEscape(version))
+	}
+
+	keys := make([]string, 0, len(quals))
+	for k, v := range quals {
+		if v != "" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for i, k := range keys {
+		sep := "&"
+		if i == 0 {
+			sep = "?"
+		}
+		b.WriteString(sep + k + "=" + purlEscape(quals[k]))
+	}
+	return b.String()
+}
+
+var purlEscaper = strings.NewReplacer("@", "%40", "+", "%2B")
+
+// purlEscape percent-encodes a purl component. "@", which separates the
+// version, and "+", which some parsers read as a space, are escaped too.
+func purlEscape(s string) string {
+	return purlEscaper.Replace(url.PathEscape(s))
+}
@@ -0,0 +1,38 @@
+package inventory_test
+
+import (
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+)
+
+func TestPackage_PURL(t *testing.T) {
+	debian := &inventory.OSRelease{ID: "debian", VersionID: "12"}
+	fedora := &inventory.OSRelease{ID: "fedora", VersionID: "39"}
+	cases := []struct {
+		pkg  inventory.Package
+		os   *inventory.OSRelease
+		want string
+	}{
+		{inventory.Package{Type: "deb", Name: "libc6", Version: "2.36-9+deb12u4", Arch: "amd64"}, debian,
+			"pkg:deb/debian/libc6@2.36-9%2Bdeb12u4?arch=amd64&distro=debian-12"},
+		{inventory.Package{Type: "apk", Name: "musl", Version: "1.2.4-r2", Arch: "x86_64"}, nil,
+			"pkg:apk/alpine/musl@1.2.4-r2?arch=x86_64"},
+		{inventory.Package{Type: "rpm", Name: "openssl-libs", Version: "1:3.1.1-4.fc39", Arch: "x86_64"}, fedora,
+			"pkg:rpm/fedora/openssl-libs@3.1.1-4.fc39?arch=x86_64&distro=fedora-39&epoch=1"},
+		{inventory.Package{Type: "go", Name: "github.com/spf13/cobra", Version: "v1.10.1"}, nil,
+			"pkg:golang/github.com/spf13/cobra@v1.10.1"},
+		{inventory.Package{Type: "python", Name: "Typing_Extensions", Version: "4.9.0"}, nil,
+			"pkg:pypi/typing-extensions@4.9.0"},
+		{inventory.Package{Type: "npm", Name: "@babel/core", Version: "7.24.0"}, nil,
+			"pkg:npm/%40babel/core@7.24.0"},
+		{inventory.Package{Type: "java", Name: "com.example:demo", Version: "1.0.0"}, nil,
+			"pkg:maven/com.example/demo@1.0.0"},
+		{inventory.Package{Type: "java", Name: "plain"}, nil, ""},
+	}
+	for _, tc := range cases {
+		if got := tc.pkg.PURL(tc.os); got != tc.want {
+			t.Errorf("PURL(%s %s) = %q, want %q", tc.pkg.Type, tc.pkg.Name, got, tc.want)
+		}
+	}
+}
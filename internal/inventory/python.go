@@ -0,0 +1,46 @@
+package inventory
+
+import (
+	"archive/tar"
+	"bufio"
+	"io"
+	"path"
+	"strings"
+)
+
+// isPythonMetadata matches the metadata of installed distributions: wheels
+// install *.dist-info/METADATA, legacy eggs *.egg-info/PKG-INFO.
+func isPythonMetadata(hdr *tar.Header) bool {
+	dir, file := path.Split(hdr.Name)
+	dir = strings.TrimSuffix(dir, "/")
+	return (file == "METADATA" && strings.HasSuffix(dir, ".dist-info")) ||
+		(file == "PKG-INFO" && strings.HasSuffix(dir, ".egg-info"))
+}
+
+// parsePythonMetadata reads a distribution's name and version from the
+// headers of its core metadata file, which end at the first blank line.
+func parsePythonMetadata(p string, r io.Reader) ([]Package, error) {
+	var name, version string
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), 1<<20)
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			break
+		}
+		k, v, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(k)) {
+		case "name":
+			name = strings.TrimSpace(v)
+		case "version":
+			version = strings.TrimSpace(v)
+		}
+	}
+	if name == "" {
+		return nil, nil
+	}
+	return []Package{{Type: "python", Name: name, Version: version, Path: p}}, nil
+}
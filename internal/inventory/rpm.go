@@ -0,0 +1,87 @@
+package inventory
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	_ "github.com/glebarez/go-sqlite" // registers the "sqlite" driver rpmdb uses
+	rpmdb "github.com/knqyf263/go-rpmdb/pkg"
+)
+
+// rpmDBs are the rpm databases: sqlite on Fedora and RHEL 9, ndb on SUSE and
+// Berkeley DB on older releases, under /var/lib/rpm or /usr/lib/sysimage/rpm.
+var rpmDBs = map[string]bool{
+	"/var/lib/rpm/rpmdb.sqlite":          true,
+	"/var/lib/rpm/Packages.db":           true,
+	"/var/lib/rpm/Packages":              true,
+	"/usr/lib/sysimage/rpm/rpmdb.sqlite": true,
+	"/usr/lib/sysimage/rpm/Packages.db":  true,
+	"/usr/lib/sysimage/rpm/Packages":     true,
+}
+
+func isRpmDB(hdr *tar.Header) bool { return rpmDBs[hdr.Name] }
+
+// parseRpmDB reads the packages in an rpm database. The database libraries
+// need a file, so the contents are staged in a temporary one.
+func parseRpmDB(p string, r io.Reader) ([]Package, error) {
+	f, err := os.CreateTemp("", "imgutil-rpmdb-*")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+
+	db, err := rpmdb.Open(f.Name())
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = db.Close() }()
+	infos, err := db.ListPackages()
+	if err != nil {
+		return nil, err
+	}
+
+	pkgs := make([]Package, 0, len(infos))
+	for _, info := range infos {
+		// gpg-pubkey entries record imported signing keys, not packages.
+		if info.Name == "gpg-pubkey" {
+			continue
+		}
+		version := info.Version + "-" + info.Release
+		if info.Epoch != nil && *info.Epoch != 0 {
+			version = fmt.Sprintf("%d:%s", *info.Epoch, version)
+		}
+		pkgs = append(pkgs, Package{
+			Type:    "rpm",
+			Name:    info.Name,
+			Version: version,
+			Arch:    info.Arch,
+			Source:  sourceRpmName(info.SourceRpm),
+			Path:    p,
+		})
+	}
+	return pkgs, nil
+}
+
+// sourceRpmName extracts the package name from a source rpm file name such
+// as "bash-5.1.8-2.fc35.src.rpm".
+func sourceRpmName(srpm string) string {
+	name := strings.TrimSuffix(srpm, ".src.rpm")
+	for range 2 {
+		i := strings.LastIndex(name, "-")
+		if i < 0 {
+			return srpm
+		}
+		name = name[:i]
+	}
+	return name
+}
@@ -0,0 +1,83 @@
+package inventory_test
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/glebarez/go-sqlite"
+)
+
+// rpmHeader encodes a minimal rpm header blob with the given string tags and
+// an epoch. Entries and their data are laid out in tag order, as rpm does.
+func rpmHeader(t *testing.T, epoch int32, tags map[int32]string) []byte {
+	t.Helper()
+	const (
+		tagEpoch  = 1003
+		typeInt32 = 4
+		typeStr   = 6
+	)
+	type entry struct{ tag, typ, offset, count int32 }
+	var (
+		entries []entry
+		data    bytes.Buffer
+	)
+	for _, tag := range []int32{1000, 1001, 1002, tagEpoch, 1022, 1044} {
+		if tag == tagEpoch {
+			for data.Len()%4 != 0 {
+				data.WriteByte(0)
+			}
+			entries = append(entries, entry{tag, typeInt32, int32(data.Len()), 1})
+			_ = binary.Write(&data, binary.BigEndian, epoch)
+			continue
+		}
+		entries = append(entries, entry{tag, typeStr, int32(data.Len()), 1})
+		data.WriteString(tags[tag] + "\x00")
+	}
+
+	var b bytes.Buffer
+	_ = binary.Write(&b, binary.BigEndian, int32(len(entries)))
+	_ = binary.Write(&b, binary.BigEndian, int32(data.Len()))
+	for _, e := range entries {
+		_ = binary.Write(&b, binary.BigEndian, e)
+	}
+	b.Write(data.Bytes())
+	return b.Bytes()
+}
+
+func TestScan_RpmSqlite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rpmdb.sqlite")
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("CREATE TABLE Packages (hnum INTEGER PRIMARY KEY AUTOINCREMENT, blob BLOB NOT NULL)"); err != nil {
+		t.Fatal(err)
+	}
+	for _, h := range [][]byte{
+		rpmHeader(t, 0, map[int32]string{1000: "bash", 1001: "5.1.8", 1002: "2.fc35", 1022: "x86_64", 1044: "bash-5.1.8-2.fc35.src.rpm"}),
+		rpmHeader(t, 1, map[int32]string{1000: "openssl-libs", 1001: "1.1.1l", 1002: "2.fc35", 1022: "x86_64", 1044: "openssl-1.1.1l-2.fc35.src.rpm"}),
+	} {
+		if _, err := db.Exec("INSERT INTO Packages (blob) VALUES (?)", h); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pkgs := scan(t, map[string]string{"var/lib/rpm/rpmdb.sqlite": string(b)})
+	if got := pkgs["bash"]; got.Type != "rpm" || got.Version != "5.1.8-2.fc35" || got.Source != "bash" || got.Arch != "x86_64" {
+		t.Errorf("got %+v", got)
+	}
+	if got := pkgs["openssl-libs"]; got.Version != "1:1.1.1l-2.fc35" || got.Source != "openssl" {
+		t.Errorf("got %+v", got)
+	}
+}
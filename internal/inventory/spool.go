@@ -0,0 +1,40 @@
+package inventory
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+)
+
+// spool makes r, which must hold at most maxReadSize bytes, readable at any
+// offset, as zip archives and ELF binaries need. Small contents stay in
+// memory; larger ones go to a temporary file that release removes.
+func spool(r io.Reader) (ra io.ReaderAt, size int64, release func(), err error) {
+	b, err := io.ReadAll(io.LimitReader(r, maxMemorySize+1))
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	if len(b) <= maxMemorySize {
+		return bytes.NewReader(b), int64(len(b)), func() {}, nil
+	}
+
+	f, err := os.CreateTemp("", "imgutil-inventory-*")
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	release = func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	size, err = io.Copy(f, io.MultiReader(bytes.NewReader(b), io.LimitReader(r, maxReadSize+1-int64(len(b)))))
+	if err != nil {
+		release()
+		return nil, 0, nil, err
+	}
+	if size > maxReadSize {
+		release()
+		return nil, 0, nil, fmt.Errorf("larger than %d bytes", maxReadSize)
+	}
+	return f, size, release, nil
+}
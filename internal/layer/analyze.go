@@ -0,0 +1,130 @@
+package layer
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"sort"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// LayerStats summarises the contents of a single layer.
+type LayerStats struct {
+	UncompressedSize int64 // size of the uncompressed tar stream
+	Files            int   // non-directory entries, excluding whiteouts
+}
+
+// WastedPath is a path whose earlier versions are hidden in the final image,
+// either overwritten by a later layer or deleted by a whiteout.
+type WastedPath struct {
+	Path  string
+	Bytes int64 // total size of the hidden versions
+	Count int   // number of hidden versions
+}
+
+// Analysis is the space usage of an image's layers.
+type Analysis struct {
+	Layers      []LayerStats
+	TotalBytes  int64 // sum of file sizes across every layer
+	WastedBytes int64 // bytes of file versions not visible in the final image
+	Wasted      []WastedPath
+}
+
+// Efficiency is the fraction of file bytes shipped in the layers that are
+// visible in the final image, in the spirit of dive's efficiency score.
+// An image with no file content is fully efficient.
+func (a *Analysis) Efficiency() float64 {
+	if a.TotalBytes == 0 {
+		return 1
+	}
+	return 1 - float64(a.WastedBytes)/float64(a.TotalBytes)
+}
+
+// Analyze streams each layer once, recording its uncompressed size and file
+// count, and works out which file versions are wasted because a later layer
+// overwrites or deletes them. Wasted is sorted by size, largest first.
+func Analyze(layers []v1.Layer) (*Analysis, error) {
+	a := &Analysis{Layers: make([]LayerStats, len(layers))}
+	live := map[string]int64{} // visible non-directory path → size
+	wasted := map[string]*WastedPath{}
+
+	waste := func(p string, size int64) {
+		w, ok := wasted[p]
+		if !ok {
+			w = &WastedPath{Path: p}
+			wasted[p] = w
+		}
+		w.Bytes += size
+		w.Count++
+		a.WastedBytes += size
+	}
+	// hide wastes the visible version of p, if any.
+	hide := func(p string) {
+		if size, ok := live[p]; ok {
+			waste(p, size)
+			delete(live, p)
+		}
+	}
+
+	// walk streams a layer through a counting reader to record its size.
+	walk := func(i int, l v1.Layer, fn WalkFunc) error {
+		rc, err := l.Uncompressed()
+		if err != nil {
+			return fmt.Errorf("opening layer: %w", err)
+		}
+		defer func() { _ = rc.Close() }()
+		cr := &countingReader{r: rc}
+		if err := walkReader(cr, fn); err != nil {
+			return err
+		}
+		// Count the tar trailer and any padding the reader stopped short of.
+		if _, err := io.Copy(io.Discard, cr); err != nil {
+			return err
+		}
+		a.Layers[i].UncompressedSize = cr.n
+		return nil
+	}
+	err := merge(layers, walk, Merger{
+		Entry: func(i int, hdr *tar.Header, _ io.Reader) error {
+			if hdr.Typeflag != tar.TypeDir {
+				a.Layers[i].Files++
+			}
+			return nil
+		},
+		Remove: func(_ int, p string) { hide(p) },
+		Add: func(_ int, hdr *tar.Header) {
+			hide(hdr.Name)
+			if hdr.Typeflag != tar.TypeDir {
+				live[hdr.Name] = hdr.Size
+				a.TotalBytes += hdr.Size
+			}
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	a.Wasted = make([]WastedPath, 0, len(wasted))
+	for _, w := range wasted {
+		a.Wasted = append(a.Wasted, *w)
+	}
+	sort.Slice(a.Wasted, func(i, j int) bool {
+		if a.Wasted[i].Bytes != a.Wasted[j].Bytes {
+			return a.Wasted[i].Bytes > a.Wasted[j].Bytes
+		}
+		return a.Wasted[i].Path < a.Wasted[j].Path
+	})
+	return a, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
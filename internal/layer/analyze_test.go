@@ -0,0 +1,55 @@
+package layer_test
+
+import (
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func TestAnalyze(t *testing.T) {
+	base := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/config", content: "0123456789"},
+		entry{name: "tmp/"},
+		entry{name: "tmp/build.tar", content: "xxxxxxxxxxxxxxxxxxxx"},
+		entry{name: "bin/app", content: "app"},
+	)
+	top := buildLayer(t,
+		entry{name: "etc/config", content: "01234"},
+		entry{name: "tmp/.wh.build.tar"},
+	)
+
+	a, err := layer.Analyze([]v1.Layer{base, top})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(a.Layers) != 2 {
+		t.Fatalf("got %d layer stats, want 2", len(a.Layers))
+	}
+	if a.Layers[0].Files != 3 || a.Layers[1].Files != 1 {
+		t.Errorf("got file counts %d and %d, want 3 and 1", a.Layers[0].Files, a.Layers[1].Files)
+	}
+	if a.Layers[0].UncompressedSize == 0 || a.Layers[0].UncompressedSize%512 != 0 {
+		t.Errorf("got uncompressed size %d, want a non-zero multiple of the tar block size", a.Layers[0].UncompressedSize)
+	}
+	if a.TotalBytes != 38 {
+		t.Errorf("got total %d bytes, want 38", a.TotalBytes)
+	}
+	if a.WastedBytes != 30 {
+		t.Errorf("got %d wasted bytes, want 30", a.WastedBytes)
+	}
+	if len(a.Wasted) != 2 || a.Wasted[0].Path != "/tmp/build.tar" || a.Wasted[1].Path != "/etc/config" {
+		t.Errorf("got wasted paths %+v, want /tmp/build.tar then /etc/config", a.Wasted)
+	}
+	if got, want := a.Efficiency(), 1-30.0/38.0; got != want {
+		t.Errorf("got efficiency %v, want %v", got, want)
+	}
+}
+
+func TestAnalysis_EfficiencyEmpty(t *testing.T) {
+	a := &layer.Analysis{}
+	if a.Efficiency() != 1 {
+		t.Errorf("got efficiency %v for an empty image, want 1", a.Efficiency())
+	}
+}
@@ -0,0 +1,273 @@
+package layer
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// WriteTar writes the merged filesystem to w as a single uncompressed tarball
+// with whiteouts applied, so it can be extracted with plain tar.
+func (fs *FS) WriteTar(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := fs.each(func(hdr *tar.Header, r io.Reader) error {
+		out := exportHeader(hdr)
+		if err := tw.WriteHeader(out); err != nil {
+			return fmt.Errorf("writing %s: %w", hdr.Name, err)
+		}
+		if out.Typeflag == tar.TypeReg {
+			if _, err := io.Copy(tw, r); err != nil {
+				return fmt.Errorf("writing %s: %w", hdr.Name, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// Extract materialises the merged filesystem beneath dir, which must be empty
+// or not yet exist. Permissions and modification times are restored, and
+// ownership too when running as root. Device nodes and FIFOs are skipped.
+//
+// Every write stays inside dir: symlinks in the image are created as-is but
+// resolved against dir, never the host root, when later entries are written
+// through them, and relative symlinks pointing above dir are refused.
+func (fs *FS) Extract(dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	existing, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%s is not empty", dir)
+	}
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+
+	x := &extractor{root: root, chown: os.Geteuid() == 0, dirs: map[string]*tar.Header{}}
+	if err := fs.each(x.write); err != nil {
+		return err
+	}
+	return x.finishDirs()
+}
+
+// each streams every layer once and calls fn, in layer order, for each entry
+// that survives into the merged filesystem. Hard links are passed last, once
+// every link target has been written.
+func (fs *FS) each(fn WalkFunc) error {
+	var links []*tar.Header
+	for i, l := range fs.layers {
+		err := Walk(l, func(hdr *tar.Header, r io.Reader) error {
+			if e, ok := fs.entries[hdr.Name]; !ok || e.Layer != i {
+				return nil
+			}
+			if hdr.Typeflag == tar.TypeLink {
+				links = append(links, hdr)
+				return nil
+			}
+			return fn(hdr, r)
+		})
+		if err != nil {
+			return fmt.Errorf("layer %d: %w", i, err)
+		}
+	}
+	for _, hdr := range links {
+		if _, ok := fs.entries[hdr.Linkname]; !ok {
+			return fmt.Errorf("hard link %s: target %s is not in the image", hdr.Name, hdr.Linkname)
+		}
+		if err := fn(hdr, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportHeader copies the fields of hdr worth keeping into a fresh header with
+// a relative name, dropping PAX path overrides left over from the source layer.
+func exportHeader(hdr *tar.Header) *tar.Header {
+	out := &tar.Header{
+		Typeflag: hdr.Typeflag,
+		Name:     strings.TrimPrefix(hdr.Name, "/"),
+		Linkname: hdr.Linkname,
+		Size:     hdr.Size,
+		Mode:     hdr.Mode,
+		Uid:      hdr.Uid,
+		Gid:      hdr.Gid,
+		Uname:    hdr.Uname,
+		Gname:    hdr.Gname,
+		ModTime:  hdr.ModTime,
+		Devmajor: hdr.Devmajor,
+		Devminor: hdr.Devminor,
+	}
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		out.Name += "/"
+	case tar.TypeLink:
+		out.Linkname = strings.TrimPrefix(hdr.Linkname, "/")
+	}
+	if out.Typeflag != tar.TypeReg {
+		out.Size = 0
+	}
+	for k, v := range hdr.PAXRecords {
+		if strings.HasPrefix(k, "SCHILY.xattr.") {
+			if out.PAXRecords == nil {
+				out.PAXRecords = map[string]string{}
+			}
+			out.PAXRecords[k] = v
+		}
+	}
+	return out
+}
+
+type extractor struct {
+	root  string
+	chown bool
+	dirs  map[string]*tar.Header // host path → header, applied once all files are written
+}
+
+func (x *extractor) write(hdr *tar.Header, r io.Reader) error {
+	target, err := x.resolve(hdr.Name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+
+	if hdr.Typeflag == tar.TypeDir {
+		if fi, err := os.Lstat(target); err == nil && !fi.IsDir() {
+			if err := os.Remove(target); err != nil {
+				return err
+			}
+		}
+		if err := os.Mkdir(target, 0o755); err != nil && !errors.Is(err, fs.ErrExist) {
+			return err
+		}
+		x.dirs[target] = hdr
+		return nil
+	}
+
+	if err := os.RemoveAll(target); err != nil {
+		return err
+	}
+	switch hdr.Typeflag {
+	case tar.TypeReg:
+		f, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600) //nolint:gosec // G304: target is resolved beneath root
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("writing %s: %w", hdr.Name, err)
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+	case tar.TypeSymlink:
+		if !path.IsAbs(hdr.Linkname) && escapes(path.Join(strings.TrimPrefix(path.Dir(hdr.Name), "/"), hdr.Linkname)) {
+			return fmt.Errorf("refusing symlink %s -> %s: target escapes the image root", hdr.Name, hdr.Linkname)
+		}
+		if err := os.Symlink(hdr.Linkname, target); err != nil {
+			return err
+		}
+		return x.setOwner(target, hdr)
+	case tar.TypeLink:
+		src, err := x.resolve(hdr.Linkname)
+		if err != nil {
+			return err
+		}
+		return os.Link(src, target)
+	default:
+		return nil // devices and FIFOs need privileges extract does not assume
+	}
+
+	if err := x.setOwner(target, hdr); err != nil {
+		return err
+	}
+	if err := os.Chmod(target, hdr.FileInfo().Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)); err != nil {
+		return err
+	}
+	return os.Chtimes(target, time.Time{}, hdr.ModTime)
+}
+
+// finishDirs applies directory modes and times deepest first, so restrictive
+// modes and child writes do not disturb them.
+func (x *extractor) finishDirs() error {
+	dirs := make([]string, 0, len(x.dirs))
+	for d := range x.dirs {
+		dirs = append(dirs, d)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(dirs)))
+	for _, d := range dirs {
+		hdr := x.dirs[d]
+		if err := x.setOwner(d, hdr); err != nil {
+			return err
+		}
+		if err := os.Chmod(d, hdr.FileInfo().Mode()&(fs.ModePerm|fs.ModeSetuid|fs.ModeSetgid|fs.ModeSticky)); err != nil {
+			return err
+		}
+		if err := os.Chtimes(d, time.Time{}, hdr.ModTime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (x *extractor) setOwner(target string, hdr *tar.Header) error {
+	if !x.chown {
+		return nil
+	}
+	return os.Lchown(target, hdr.Uid, hdr.Gid)
+}
+
+// resolve maps the image path p to a host path beneath root. Symlinks already
+// extracted into p's parent directories are followed the way the image would
+// see them: absolute targets restart at root and ".." stops at root. The final
+// element is never followed.
+func (x *extractor) resolve(p string) (string, error) {
+	dir, base := path.Split(Clean(p))
+	pending := strings.Split(dir, "/")
+	resolved := "/"
+	hops := 0
+	for len(pending) > 0 {
+		name := pending[0]
+		pending = pending[1:]
+		if name == "" || name == "." {
+			continue
+		}
+		next := path.Join(resolved, name)
+		host := filepath.Join(x.root, filepath.FromSlash(next))
+		fi, err := os.Lstat(host)
+		if err != nil || fi.Mode()&fs.ModeSymlink == 0 {
+			resolved = next
+			continue
+		}
+		if hops++; hops > maxSymlinks {
+			return "", fmt.Errorf("%s: too many levels of symbolic links", p)
+		}
+		link, err := os.Readlink(host)
+		if err != nil {
+			return "", err
+		}
+		if path.IsAbs(link) {
+			resolved = "/"
+		}
+		pending = append(strings.Split(link, "/"), pending...)
+	}
+	return filepath.Join(x.root, filepath.FromSlash(path.Join(resolved, base))), nil
+}
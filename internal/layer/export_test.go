@@ -0,0 +1,136 @@
+package layer_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func TestFS_Extract(t *testing.T) {
+	base := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/passwd", content: "root"},
+		entry{name: "etc/hosts", content: "127.0.0.1"},
+		entry{name: "usr/lib/"},
+		entry{name: "lib", link: "/usr/lib"},
+	)
+	top := buildLayer(t,
+		entry{name: "etc/.wh.passwd"},
+		entry{name: "lib/libc.so", content: "elf"},
+		entry{name: "etc/hosts.bak", link: "/etc/hosts", hard: true},
+	)
+	fs, err := layer.Flatten([]v1.Layer{base, top})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := fs.Extract(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Lstat(filepath.Join(dir, "etc/passwd")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("whited-out /etc/passwd was extracted: %v", err)
+	}
+	if link, err := os.Readlink(filepath.Join(dir, "lib")); err != nil || link != "/usr/lib" {
+		t.Errorf("got symlink %q (%v), want /usr/lib", link, err)
+	}
+	// /lib/libc.so must land in the image's /usr/lib, not the host's.
+	got, err := os.ReadFile(filepath.Join(dir, "usr/lib/libc.so"))
+	if err != nil || string(got) != "elf" {
+		t.Errorf("got %q (%v) for /usr/lib/libc.so, want elf", got, err)
+	}
+
+	hosts, err := os.Stat(filepath.Join(dir, "etc/hosts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hosts.Mode().Perm() != 0o644 {
+		t.Errorf("got mode %v, want 0644", hosts.Mode().Perm())
+	}
+	bak, err := os.Stat(filepath.Join(dir, "etc/hosts.bak"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !os.SameFile(hosts, bak) {
+		t.Error("hard link was not preserved")
+	}
+}
+
+func TestFS_ExtractRefusesEscapingSymlink(t *testing.T) {
+	fs, err := layer.Flatten([]v1.Layer{buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/evil", link: "../../outside"},
+	)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Extract(t.TempDir()); err == nil {
+		t.Error("expected error for a symlink escaping the root")
+	}
+}
+
+func TestFS_ExtractRequiresEmptyDir(t *testing.T) {
+	fs, err := layer.Flatten([]v1.Layer{buildLayer(t, entry{name: "a", content: "a"})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "existing"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.Extract(dir); err == nil {
+		t.Error("expected error extracting into a non-empty directory")
+	}
+}
+
+func TestFS_WriteTar(t *testing.T) {
+	base := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/passwd", content: "root"},
+		entry{name: "etc/hosts", content: "a"},
+	)
+	top := buildLayer(t,
+		entry{name: "etc/.wh.passwd"},
+		entry{name: "etc/hosts", content: "b"},
+	)
+	fs, err := layer.Flatten([]v1.Layer{base, top})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := fs.WriteTar(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string]string{}
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(tr)
+		got[hdr.Name] = string(b)
+	}
+	want := map[string]string{"etc/": "", "etc/hosts": "b"}
+	if len(got) != len(want) {
+		t.Fatalf("got entries %v, want %v", got, want)
+	}
+	for name, content := range want {
+		if got[name] != content {
+			t.Errorf("%s: got %q, want %q", name, got[name], content)
+		}
+	}
+}
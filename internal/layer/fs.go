@@ -0,0 +1,289 @@
+package layer
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Entry is a single path in a flattened image filesystem.
+type Entry struct {
+	Header *tar.Header
+	Layer  int    // index of the layer that last wrote the path, -1 if implied
+	Digest string // sha256 of the contents, regular files only
+}
+
+// FS is the merged view of an image's layers with whiteouts applied. Only
+// headers and, when requested, content digests are kept in memory.
+type FS struct {
+	entries map[string]*Entry
+	layers  []v1.Layer
+}
+
+// Flatten streams every layer once and merges them into a single filesystem
+// view, hashing the contents of every regular file so Diff can compare them.
+func Flatten(layers []v1.Layer) (*FS, error) {
+	return flatten(layers, true)
+}
+
+// FlattenHeaders is Flatten without the content digests: only tar headers are
+// read, so entries' Digest is empty. It suits listing and copying files, not
+// Diff.
+func FlattenHeaders(layers []v1.Layer) (*FS, error) {
+	return flatten(layers, false)
+}
+
+func flatten(layers []v1.Layer, digests bool) (*FS, error) {
+	fs := &FS{entries: map[string]*Entry{}, layers: layers}
+	pending := map[*tar.Header]*Entry{}
+
+	err := Merge(layers, Merger{
+		Entry: func(i int, hdr *tar.Header, r io.Reader) error {
+			e := &Entry{Header: hdr, Layer: i}
+			if digests && hdr.Typeflag == tar.TypeReg {
+				h := sha256.New()
+				if _, err := io.Copy(h, r); err != nil {
+					return fmt.Errorf("reading %s: %w", hdr.Name, err)
+				}
+				e.Digest = "sha256:" + hex.EncodeToString(h.Sum(nil))
+			}
+			pending[hdr] = e
+			return nil
+		},
+		Remove: func(_ int, p string) { delete(fs.entries, p) },
+		Add: func(_ int, hdr *tar.Header) {
+			fs.entries[hdr.Name] = pending[hdr]
+			delete(pending, hdr)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// Lookup returns the entry at the absolute path p, if present.
+func (fs *FS) Lookup(p string) (*Entry, bool) {
+	e, ok := fs.entries[Clean(p)]
+	return e, ok
+}
+
+// Paths returns every path in the filesystem in lexical order.
+func (fs *FS) Paths() []string {
+	paths := make([]string, 0, len(fs.entries))
+	for p := range fs.entries {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Diff compares two flattened filesystems and reports what b adds, modifies
+// and deletes relative to a. Entries count as modified when their type, mode,
+// ownership, link target or contents differ.
+func Diff(a, b *FS) []Change {
+	var changes []Change
+	for p, eb := range b.entries {
+		ea, ok := a.entries[p]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Path: p, Kind: Added})
+		case !sameEntry(ea, eb):
+			changes = append(changes, Change{Path: p, Kind: Modified})
+		}
+	}
+	for p := range a.entries {
+		if _, ok := b.entries[p]; !ok {
+			changes = append(changes, Change{Path: p, Kind: Deleted})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Path < changes[j].Path })
+	return changes
+}
+
+func sameEntry(a, b *Entry) bool {
+	ha, hb := a.Header, b.Header
+	return ha.Typeflag == hb.Typeflag &&
+		ha.Mode == hb.Mode &&
+		ha.Uid == hb.Uid &&
+		ha.Gid == hb.Gid &&
+		ha.Linkname == hb.Linkname &&
+		ha.Size == hb.Size &&
+		a.Digest == b.Digest
+}
+
+// maxSymlinks bounds symlink resolution, matching Linux's MAXSYMLINKS.
+const maxSymlinks = 40
+
+// ErrNotExist reports a path missing from an image's filesystem. It is not
+// fs.ErrNotExist, which callers report as a missing image or archive.
+var ErrNotExist = errors.New("no such file or directory")
+
+// errStopWalk ends a Walk early once the wanted entry has been handled.
+var errStopWalk = errors.New("stop walk")
+
+// ReadDir returns the entries directly beneath dir, sorted by path. Parent
+// directories that have no tar entry of their own are synthesised.
+func (fs *FS) ReadDir(dir string) ([]*Entry, error) {
+	dir = Clean(dir)
+	if dir != "/" {
+		e, ok := fs.entries[dir]
+		if ok && e.Header.Typeflag != tar.TypeDir {
+			return nil, fmt.Errorf("%s: not a directory", dir)
+		}
+		if !ok && !fs.hasChildren(dir) {
+			return nil, fmt.Errorf("%s: %w", dir, ErrNotExist)
+		}
+	}
+
+	children := map[string]*Entry{}
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for p, e := range fs.entries {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok || rest == "" {
+			continue
+		}
+		name, _, nested := strings.Cut(rest, "/")
+		child := prefix + name
+		if !nested {
+			children[child] = e
+		} else if _, seen := children[child]; !seen {
+			children[child] = implicitDir(child)
+		}
+	}
+
+	out := make([]*Entry, 0, len(children))
+	for _, e := range children {
+		out = append(out, e)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Header.Name < out[j].Header.Name })
+	return out, nil
+}
+
+// Stat returns the entry at p without following a final symlink. Directories
+// implied by their children but missing from the tarballs are synthesised.
+func (fs *FS) Stat(p string) (*Entry, error) {
+	p = Clean(p)
+	if e, ok := fs.entries[p]; ok {
+		return e, nil
+	}
+	if p == "/" || fs.hasChildren(p) {
+		return implicitDir(p), nil
+	}
+	return nil, fmt.Errorf("%s: %w", p, ErrNotExist)
+}
+
+// Copy streams the contents of the regular file at p to w, following
+// symlinks (within the image) and hard links. Only the layer that last wrote
+// the file is read.
+func (fs *FS) Copy(w io.Writer, p string) (int64, error) {
+	e, err := fs.resolve(Clean(p))
+	if err != nil {
+		return 0, err
+	}
+	if e.Header.Typeflag == tar.TypeLink {
+		if e, err = fs.resolve(Clean(e.Header.Linkname)); err != nil {
+			return 0, err
+		}
+	}
+	if e.Header.Typeflag != tar.TypeReg {
+		return 0, fmt.Errorf("%s: not a regular file", e.Header.Name)
+	}
+
+	var n int64
+	found := false
+	err = Walk(fs.layers[e.Layer], func(hdr *tar.Header, r io.Reader) error {
+		if hdr.Name != e.Header.Name {
+			return nil
+		}
+		found = true
+		n, err = io.Copy(w, r)
+		if err != nil {
+			return err
+		}
+		return errStopWalk
+	})
+	if err != nil && !errors.Is(err, errStopWalk) {
+		return n, err
+	}
+	if !found {
+		return 0, fmt.Errorf("%s: missing from layer %d", e.Header.Name, e.Layer)
+	}
+	return n, nil
+}
+
+// resolve follows symlinks at p, including in its parent directories, and
+// returns the final entry. Absolute link targets are resolved against the
+// image root, never the host.
+func (fs *FS) resolve(p string) (*Entry, error) {
+	for hops := 0; hops <= maxSymlinks; hops++ {
+		resolved, err := fs.resolveParents(p, hops)
+		if err != nil {
+			return nil, err
+		}
+		e, ok := fs.entries[resolved]
+		if !ok {
+			return nil, fmt.Errorf("%s: %w", p, ErrNotExist)
+		}
+		if e.Header.Typeflag != tar.TypeSymlink {
+			return e, nil
+		}
+		p = linkTarget(resolved, e.Header.Linkname)
+	}
+	return nil, fmt.Errorf("%s: too many levels of symbolic links", p)
+}
+
+// resolveParents follows symlinked directories along p, leaving the final
+// element untouched.
+func (fs *FS) resolveParents(p string, hops int) (string, error) {
+	dir, base := path.Split(p)
+	if dir == "/" || dir == "" {
+		return p, nil
+	}
+	parent := path.Clean(dir)
+	for ; hops <= maxSymlinks; hops++ {
+		resolved, err := fs.resolveParents(parent, hops)
+		if err != nil {
+			return "", err
+		}
+		e, ok := fs.entries[resolved]
+		if !ok || e.Header.Typeflag != tar.TypeSymlink {
+			return path.Join(resolved, base), nil
+		}
+		parent = linkTarget(resolved, e.Header.Linkname)
+	}
+	return "", fmt.Errorf("%s: too many levels of symbolic links", p)
+}
+
+// linkTarget resolves a symlink's target relative to the link's directory.
+func linkTarget(link, target string) string {
+	if path.IsAbs(target) {
+		return Clean(target)
+	}
+	return Clean(path.Join(path.Dir(link), target))
+}
+
+func (fs *FS) hasChildren(dir string) bool {
+	prefix := dir + "/"
+	for p := range fs.entries {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func implicitDir(p string) *Entry {
+	return &Entry{
+		Header: &tar.Header{Name: p, Typeflag: tar.TypeDir, Mode: 0o755},
+		Layer:  -1,
+	}
+}
@@ -0,0 +1,191 @@
+package layer_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func TestFlatten(t *testing.T) {
+	base := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/hosts", content: "a"},
+		entry{name: "etc/passwd", content: "root"},
+		entry{name: "var/cache/"},
+		entry{name: "var/cache/pkg", content: "x"},
+	)
+	top := buildLayer(t,
+		entry{name: "etc/hosts", content: "b"},
+		entry{name: "etc/.wh.passwd"},
+		entry{name: "var/cache/.wh..wh..opq"},
+		entry{name: "var/cache/new", content: "y"},
+	)
+
+	fs, err := layer.Flatten([]v1.Layer{base, top})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fs.Lookup("/etc/passwd"); ok {
+		t.Error("whited-out /etc/passwd still present")
+	}
+	if _, ok := fs.Lookup("/var/cache/pkg"); ok {
+		t.Error("opaque directory contents still present")
+	}
+	if _, ok := fs.Lookup("/var/cache/new"); !ok {
+		t.Error("file added alongside opaque whiteout missing")
+	}
+	hosts, ok := fs.Lookup("etc/hosts")
+	if !ok {
+		t.Fatal("/etc/hosts missing")
+	}
+	if hosts.Layer != 1 {
+		t.Errorf("got /etc/hosts from layer %d, want 1", hosts.Layer)
+	}
+}
+
+func TestFlattenHeaders(t *testing.T) {
+	layers := []v1.Layer{
+		buildLayer(t, entry{name: "etc/hosts", content: "a"}),
+		buildLayer(t, entry{name: "etc/hosts", content: "b"}),
+	}
+
+	fs, err := layer.FlattenHeaders(layers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hosts, ok := fs.Lookup("/etc/hosts")
+	if !ok {
+		t.Fatal("/etc/hosts missing")
+	}
+	if hosts.Digest != "" {
+		t.Errorf("got digest %q, want none", hosts.Digest)
+	}
+	var buf bytes.Buffer
+	if _, err := fs.Copy(&buf, "/etc/hosts"); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "b" {
+		t.Errorf("got %q, want %q", buf.String(), "b")
+	}
+
+	hashed, err := layer.Flatten(layers)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := hashed.Lookup("/etc/hosts"); e.Digest == "" {
+		t.Error("Flatten left /etc/hosts without a digest")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a, err := layer.Flatten([]v1.Layer{buildLayer(t,
+		entry{name: "etc/hosts", content: "a"},
+		entry{name: "etc/passwd", content: "root"},
+		entry{name: "etc/motd", content: "same"},
+	)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := layer.Flatten([]v1.Layer{buildLayer(t,
+		entry{name: "etc/hosts", content: "b"},
+		entry{name: "etc/motd", content: "same"},
+		entry{name: "etc/issue", content: "new"},
+	)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []layer.Change{
+		{Path: "/etc/hosts", Kind: layer.Modified},
+		{Path: "/etc/issue", Kind: layer.Added},
+		{Path: "/etc/passwd", Kind: layer.Deleted},
+	}
+	got := layer.Diff(a, b)
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("change %d: got %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestFS_ReadDir(t *testing.T) {
+	fs, err := layer.Flatten([]v1.Layer{buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/hosts", content: "a"},
+		entry{name: "usr/lib/libc.so", content: "elf"},
+	)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, err := fs.ReadDir("/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(root) != 2 || root[0].Header.Name != "/etc" || root[1].Header.Name != "/usr" {
+		t.Fatalf("got root entries %v, want [/etc /usr]", names(root))
+	}
+	if root[1].Layer != -1 {
+		t.Errorf("implied /usr should have layer -1, got %d", root[1].Layer)
+	}
+
+	if _, err := fs.ReadDir("/etc/hosts"); err == nil {
+		t.Error("expected error reading a file as a directory")
+	}
+	if _, err := fs.ReadDir("/missing"); !errors.Is(err, layer.ErrNotExist) {
+		t.Errorf("got %v, want layer.ErrNotExist", err)
+	}
+}
+
+func TestFS_Copy(t *testing.T) {
+	base := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/os-release", content: "old"},
+		entry{name: "usr/lib/os-release", content: "ID=test"},
+	)
+	top := buildLayer(t,
+		entry{name: "etc/os-release", link: "../usr/lib/os-release"},
+		entry{name: "etc/abs", link: "/usr/lib/os-release"},
+		entry{name: "lib", link: "usr/lib"},
+		entry{name: "loop", link: "loop"},
+	)
+	fs, err := layer.Flatten([]v1.Layer{base, top})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, p := range []string{"/usr/lib/os-release", "/etc/os-release", "/etc/abs", "/lib/os-release"} {
+		var buf bytes.Buffer
+		if _, err := fs.Copy(&buf, p); err != nil {
+			t.Errorf("%s: %v", p, err)
+			continue
+		}
+		if buf.String() != "ID=test" {
+			t.Errorf("%s: got %q, want %q", p, buf.String(), "ID=test")
+		}
+	}
+
+	if _, err := fs.Copy(&bytes.Buffer{}, "/etc"); err == nil {
+		t.Error("expected error copying a directory")
+	}
+	if _, err := fs.Copy(&bytes.Buffer{}, "/loop"); err == nil {
+		t.Error("expected error following a symlink loop")
+	}
+	if _, err := fs.Copy(&bytes.Buffer{}, "/missing"); !errors.Is(err, layer.ErrNotExist) {
+		t.Errorf("got %v, want layer.ErrNotExist", err)
+	}
+}
+
+func names(entries []*layer.Entry) []string {
+	out := make([]string, 0, len(entries))
+	for _, e := range entries {
+		out = append(out, e.Header.Name)
+	}
+	return out
+}
@@ -0,0 +1,173 @@
+// Package layer reads filesystem contents and changes out of image layer tarballs.
+package layer
+
+import (
+	"archive/tar"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+const (
+	whiteoutPrefix = ".wh."
+	opaqueWhiteout = ".wh..wh..opq"
+)
+
+// ChangeKind describes how a layer changed a path relative to the layers below it.
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Modified ChangeKind = "modified"
+	Deleted  ChangeKind = "deleted"
+)
+
+// Change is a single path added, modified or deleted by a layer.
+type Change struct {
+	Path string
+	Kind ChangeKind
+}
+
+// WalkFunc is called for every entry in a layer tarball. r reads the entry's
+// contents and is only valid until WalkFunc returns.
+type WalkFunc func(hdr *tar.Header, r io.Reader) error
+
+// Walk streams the uncompressed tarball of l, calling fn for each entry in order.
+// Entry names and hard link targets are cleaned to absolute paths
+// ("/etc/passwd") before fn sees them; names that climb out of the layer root
+// with ".." are rejected rather than silently clamped.
+func Walk(l v1.Layer, fn WalkFunc) error {
+	rc, err := l.Uncompressed()
+	if err != nil {
+		return fmt.Errorf("opening layer: %w", err)
+	}
+	defer func() { _ = rc.Close() }()
+	return walkReader(rc, fn)
+}
+
+// walkReader is Walk over an already-open uncompressed tar stream.
+func walkReader(r io.Reader, fn WalkFunc) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("reading layer tarball: %w", err)
+		}
+		if escapes(hdr.Name) {
+			return fmt.Errorf("unsafe path %q in layer tarball", hdr.Name)
+		}
+		hdr.Name = Clean(hdr.Name)
+		if hdr.Name == "/" {
+			continue
+		}
+		if hdr.Typeflag == tar.TypeLink {
+			if escapes(hdr.Linkname) {
+				return fmt.Errorf("unsafe hard link target %q in layer tarball", hdr.Linkname)
+			}
+			hdr.Linkname = Clean(hdr.Linkname)
+		}
+		if err := fn(hdr, tr); err != nil {
+			return err
+		}
+	}
+}
+
+// Clean normalises a tar entry name to an absolute, slash-separated path.
+func Clean(name string) string {
+	return path.Clean("/" + name)
+}
+
+// escapes reports whether the slash-separated path p, taken relative to the
+// root, climbs above it.
+func escapes(p string) bool {
+	rel := path.Clean(strings.TrimLeft(p, "/"))
+	return rel == ".." || strings.HasPrefix(rel, "../")
+}
+
+// Whiteout reports whether p is a whiteout entry. For a regular whiteout it
+// returns the path being deleted; for an opaque whiteout it returns the
+// directory whose lower contents are hidden, with opaque set.
+func Whiteout(p string) (target string, opaque, ok bool) {
+	dir, base := path.Split(p)
+	dir = path.Clean(dir)
+	if base == opaqueWhiteout {
+		return dir, true, true
+	}
+	if strings.HasPrefix(base, whiteoutPrefix) {
+		return path.Join(dir, strings.TrimPrefix(base, whiteoutPrefix)), false, true
+	}
+	return "", false, false
+}
+
+// Changes streams each layer once and classifies every path it touches as
+// added, modified or deleted relative to the layers below it. Directories that
+// already exist below are not reported, since most layers re-list their parents.
+// For deletions only the topmost removed path is reported, not its children.
+func Changes(layers []v1.Layer) ([][]Change, error) {
+	present := map[string]bool{} // path → is directory
+	changes := make([]map[string]ChangeKind, len(layers))
+	// removed holds the paths each layer removed, and whether each was a
+	// directory. Merge removes a layer's paths before adding its entries.
+	removed := make([]map[string]bool, len(layers))
+	for i := range layers {
+		changes[i] = map[string]ChangeKind{}
+		removed[i] = map[string]bool{}
+	}
+
+	err := Merge(layers, Merger{
+		Remove: func(i int, p string) {
+			removed[i][p] = present[p]
+			delete(present, p)
+		},
+		Add: func(i int, hdr *tar.Header) {
+			isDir := hdr.Typeflag == tar.TypeDir
+			wasDir, existed := present[hdr.Name]
+			wasRemoved := false
+			if !existed {
+				wasDir, wasRemoved = removed[i][hdr.Name]
+				existed = wasRemoved
+			}
+			switch {
+			case changes[i][hdr.Name] == Added:
+				// Listed twice in one layer.
+			case !existed:
+				changes[i][hdr.Name] = Added
+			case isDir && wasDir:
+				if wasRemoved {
+					changes[i][hdr.Name] = Modified
+				}
+			default:
+				changes[i][hdr.Name] = Modified
+			}
+			present[hdr.Name] = isDir
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]Change, len(layers))
+	for i := range layers {
+		for p := range removed[i] {
+			_, parentRemoved := removed[i][path.Dir(p)]
+			if _, changed := changes[i][p]; !changed && !parentRemoved {
+				changes[i][p] = Deleted
+			}
+		}
+		list := make([]Change, 0, len(changes[i]))
+		for p, kind := range changes[i] {
+			list = append(list, Change{Path: p, Kind: kind})
+		}
+		sort.Slice(list, func(a, b int) bool { return list[a].Path < list[b].Path })
+		out[i] = list
+	}
+	return out, nil
+}
@@ -0,0 +1,160 @@
+package layer_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"io"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+// entry describes a tar entry; names ending in "/" are directories and
+// entries with a link are symlinks to it, or hard links when hard is set.
+type entry struct {
+	name    string
+	content string
+	link    string
+	hard    bool
+}
+
+func buildLayer(t *testing.T, entries ...entry) v1.Layer {
+	t.Helper()
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0o644, Typeflag: tar.TypeReg, Size: int64(len(e.content))}
+		if e.name[len(e.name)-1] == '/' {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Mode = 0o755
+			hdr.Size = 0
+		}
+		if e.link != "" {
+			hdr.Typeflag = tar.TypeSymlink
+			hdr.Linkname = e.link
+			hdr.Mode = 0o777
+			hdr.Size = 0
+			if e.hard {
+				hdr.Typeflag = tar.TypeLink
+				hdr.Mode = 0o644
+			}
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := buf.Bytes()
+	l, err := tarball.LayerFromOpener(func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(b)), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestWalk_CleansNames(t *testing.T) {
+	l := buildLayer(t, entry{name: "./etc/"}, entry{name: "./etc/hosts", content: "127.0.0.1"})
+
+	var names []string
+	err := layer.Walk(l, func(hdr *tar.Header, r io.Reader) error {
+		names = append(names, hdr.Name)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 || names[0] != "/etc" || names[1] != "/etc/hosts" {
+		t.Errorf("got names %v, want [/etc /etc/hosts]", names)
+	}
+}
+
+func TestWalk_RejectsTraversal(t *testing.T) {
+	for _, l := range []v1.Layer{
+		buildLayer(t, entry{name: "../../etc/passwd", content: "x"}),
+		buildLayer(t, entry{name: "etc/passwd", link: "../../../etc/shadow", hard: true}),
+	} {
+		err := layer.Walk(l, func(*tar.Header, io.Reader) error { return nil })
+		if err == nil {
+			t.Error("expected error for a path escaping the layer root")
+		}
+	}
+}
+
+func TestWhiteout(t *testing.T) {
+	cases := []struct {
+		path   string
+		target string
+		opaque bool
+		ok     bool
+	}{
+		{"/etc/.wh.hosts", "/etc/hosts", false, true},
+		{"/var/cache/.wh..wh..opq", "/var/cache", true, true},
+		{"/etc/hosts", "", false, false},
+	}
+	for _, tc := range cases {
+		target, opaque, ok := layer.Whiteout(tc.path)
+		if target != tc.target || opaque != tc.opaque || ok != tc.ok {
+			t.Errorf("Whiteout(%q) = (%q, %v, %v), want (%q, %v, %v)",
+				tc.path, target, opaque, ok, tc.target, tc.opaque, tc.ok)
+		}
+	}
+}
+
+func TestChanges(t *testing.T) {
+	base := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/hosts", content: "a"},
+		entry{name: "etc/passwd", content: "root"},
+		entry{name: "var/"},
+		entry{name: "var/cache/"},
+		entry{name: "var/cache/apt/"},
+		entry{name: "var/cache/apt/pkg", content: "x"},
+	)
+	top := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/hosts", content: "b"},
+		entry{name: "etc/.wh.passwd"},
+		entry{name: "etc/motd", content: "hi"},
+		entry{name: "var/cache/.wh..wh..opq"},
+	)
+
+	changes, err := layer.Changes([]v1.Layer{base, top})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("got %d layers of changes, want 2", len(changes))
+	}
+	if len(changes[0]) != 7 {
+		t.Errorf("base layer: got %d changes, want 7: %v", len(changes[0]), changes[0])
+	}
+	for _, c := range changes[0] {
+		if c.Kind != layer.Added {
+			t.Errorf("base layer: %s is %s, want added", c.Path, c.Kind)
+		}
+	}
+
+	want := []layer.Change{
+		{Path: "/etc/hosts", Kind: layer.Modified},
+		{Path: "/etc/motd", Kind: layer.Added},
+		{Path: "/etc/passwd", Kind: layer.Deleted},
+		{Path: "/var/cache/apt", Kind: layer.Deleted},
+	}
+	if len(changes[1]) != len(want) {
+		t.Fatalf("top layer: got %v, want %v", changes[1], want)
+	}
+	for i := range want {
+		if changes[1][i] != want[i] {
+			t.Errorf("top layer change %d: got %v, want %v", i, changes[1][i], want[i])
+		}
+	}
+}
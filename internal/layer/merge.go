@@ -0,0 +1,101 @@
+package layer
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+)
+
+// Merger receives the steps Merge takes to build the merged filesystem. Any
+// of its functions may be nil.
+type Merger struct {
+	// Entry is called for each entry of layer i other than whiteouts, in
+	// order, while the layer is streamed. r reads the entry's contents and is
+	// only valid until Entry returns.
+	Entry func(i int, hdr *tar.Header, r io.Reader) error
+	// Remove is called, once layer i has been streamed, for each path of a
+	// lower layer that it hides: deleted by a whiteout, beneath an opaque
+	// directory, or beneath a directory a non-directory replaces.
+	Remove func(i int, p string)
+	// Add is called for each entry of layer i after the removals, in order.
+	// An entry replacing a path is added without that path being removed.
+	Add func(i int, hdr *tar.Header)
+}
+
+// Merge streams every layer once and applies them in order, the way a
+// container runtime stacks them, reporting each step to m.
+func Merge(layers []v1.Layer, m Merger) error {
+	return merge(layers, func(_ int, l v1.Layer, fn WalkFunc) error { return Walk(l, fn) }, m)
+}
+
+// merge is Merge with the walk of each layer supplied by the caller.
+func merge(layers []v1.Layer, walk func(i int, l v1.Layer, fn WalkFunc) error, m Merger) error {
+	present := map[string]bool{} // path → is directory
+
+	for i, l := range layers {
+		var (
+			added     []*tar.Header
+			whiteouts []string
+			opaques   []string
+		)
+		err := walk(i, l, func(hdr *tar.Header, r io.Reader) error {
+			if target, opaque, ok := Whiteout(hdr.Name); ok {
+				if opaque {
+					opaques = append(opaques, target)
+				} else {
+					whiteouts = append(whiteouts, target)
+				}
+				return nil
+			}
+			added = append(added, hdr)
+			if m.Entry != nil {
+				return m.Entry(i, hdr, r)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("layer %d: %w", i, err)
+		}
+
+		removed := func(p string) {
+			if m.Remove != nil {
+				m.Remove(i, p)
+			}
+		}
+		for _, target := range whiteouts {
+			removeTree(present, target, true, removed)
+		}
+		for _, dir := range opaques {
+			removeTree(present, dir, false, removed)
+		}
+		for _, hdr := range added {
+			isDir := hdr.Typeflag == tar.TypeDir
+			if wasDir, ok := present[hdr.Name]; ok && wasDir && !isDir {
+				removeTree(present, hdr.Name, false, removed)
+			}
+			present[hdr.Name] = isDir
+			if m.Add != nil {
+				m.Add(i, hdr)
+			}
+		}
+	}
+	return nil
+}
+
+// removeTree deletes everything beneath dir from m, and dir itself when self
+// is set, calling removed for each path deleted.
+func removeTree(m map[string]bool, dir string, self bool, removed func(p string)) {
+	prefix := dir + "/"
+	if dir == "/" {
+		prefix = "/"
+	}
+	for p := range m {
+		if (self && p == dir) || strings.HasPrefix(p, prefix) {
+			delete(m, p)
+			removed(p)
+		}
+	}
+}
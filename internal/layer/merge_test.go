@@ -0,0 +1,62 @@
+package layer_test
+
+import (
+	"archive/tar"
+	"io"
+	"slices"
+	"testing"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+func TestMerge(t *testing.T) {
+	base := buildLayer(t,
+		entry{name: "etc/"},
+		entry{name: "etc/hosts", content: "a"},
+		entry{name: "opt/app/"},
+		entry{name: "opt/app/bin", content: "x"},
+		entry{name: "var/cache/"},
+		entry{name: "var/cache/pkg", content: "y"},
+	)
+	top := buildLayer(t,
+		entry{name: "etc/.wh.hosts"},
+		entry{name: "opt/app", content: "now a file"},
+		entry{name: "var/cache/.wh..wh..opq"},
+		entry{name: "var/cache/new", content: "z"},
+	)
+
+	var entries, removed, added []string
+	err := layer.Merge([]v1.Layer{base, top}, layer.Merger{
+		Entry: func(i int, hdr *tar.Header, r io.Reader) error {
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return err
+			}
+			if i == 1 && hdr.Typeflag == tar.TypeReg {
+				entries = append(entries, hdr.Name+"="+string(b))
+			}
+			return nil
+		},
+		Remove: func(_ int, p string) { removed = append(removed, p) },
+		Add: func(i int, hdr *tar.Header) {
+			if i == 1 {
+				added = append(added, hdr.Name)
+			}
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := []string{"/opt/app=now a file", "/var/cache/new=z"}; !slices.Equal(entries, want) {
+		t.Errorf("got entries %v, want %v", entries, want)
+	}
+	slices.Sort(removed)
+	if want := []string{"/etc/hosts", "/opt/app/bin", "/var/cache/pkg"}; !slices.Equal(removed, want) {
+		t.Errorf("got removed %v, want %v", removed, want)
+	}
+	if want := []string{"/opt/app", "/var/cache/new"}; !slices.Equal(added, want) {
+		t.Errorf("got added %v, want %v", added, want)
+	}
+}
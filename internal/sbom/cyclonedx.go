@@ -0,0 +1,119 @@
+package sbom
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+// CycloneDX 1.5 JSON (https://cyclonedx.org/docs/1.5/json/). The image is
+// the metadata component; packages record their layer as properties.
+
+type cdxDoc struct {
+	Schema       string          `json:"$schema"`
+	BOMFormat    string          `json:"bomFormat"`
+	SpecVersion  string          `json:"specVersion"`
+	SerialNumber string          `json:"serialNumber"`
+	Version      int             `json:"version"`
+	Metadata     cdxMetadata     `json:"metadata"`
+	Components   []cdxComponent  `json:"components"`
+	Dependencies []cdxDependency `json:"dependencies"`
+}
+
+type cdxMetadata struct {
+	Timestamp string       `json:"timestamp"`
+	Tools     cdxTools     `json:"tools"`
+	Component cdxComponent `json:"component"`
+}
+
+type cdxTools struct {
+	Components []cdxComponent `json:"components"`
+}
+
+type cdxComponent struct {
+	BOMRef     string        `json:"bom-ref,omitempty"`
+	Type       string        `json:"type"`
+	Name       string        `json:"name"`
+	Version    string        `json:"version,omitempty"`
+	Hashes     []cdxHash     `json:"hashes,omitempty"`
+	PURL       string        `json:"purl,omitempty"`
+	Properties []cdxProperty `json:"properties,omitempty"`
+}
+
+type cdxHash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+type cdxProperty struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn"`
+}
+
+func cyclonedxDocument(img Image, inv *inventory.Inventory, created time.Time) cdxDoc {
+	root := cdxComponent{
+		BOMRef:  "image",
+		Type:    "container",
+		Name:    img.Reference,
+		Version: img.Digest.String(),
+		Hashes:  []cdxHash{{Alg: "SHA-256", Content: img.Digest.Hex}},
+		PURL:    imagePURL(img),
+	}
+	doc := cdxDoc{
+		Schema:       "http://cyclonedx.org/schema/bom-1.5.schema.json",
+		BOMFormat:    "CycloneDX",
+		SpecVersion:  "1.5",
+		SerialNumber: "urn:uuid:" + uuid5("imgutil:"+img.Digest.String()),
+		Version:      1,
+		Metadata: cdxMetadata{
+			Timestamp: timestamp(created),
+			Tools: cdxTools{Components: []cdxComponent{
+				{Type: "application", Name: "imgutil", Version: version.Version},
+			}},
+			Component: root,
+		},
+		Components: []cdxComponent{},
+	}
+	deps := []string{}
+
+	if inv.OS != nil && inv.OS.ID != "" {
+		doc.Components = append(doc.Components, cdxComponent{
+			BOMRef:  "os",
+			Type:    "operating-system",
+			Name:    inv.OS.ID,
+			Version: inv.OS.VersionID,
+		})
+		deps = append(deps, "os")
+	}
+
+	ids := packageIDs(inv.Packages)
+	for i, p := range inv.Packages {
+		c := cdxComponent{
+			BOMRef:  "pkg-" + ids[i],
+			Type:    "library",
+			Name:    p.Name,
+			Version: p.Version,
+			PURL:    p.PURL(inv.OS),
+			Properties: []cdxProperty{
+				{Name: "imgutil:package:type", Value: p.Type},
+				{Name: "imgutil:package:path", Value: p.Path},
+				{Name: "imgutil:layer:index", Value: strconv.Itoa(p.Layer)},
+			},
+		}
+		if p.Layer < len(img.Layers) {
+			c.Properties = append(c.Properties, cdxProperty{Name: "imgutil:layer:digest", Value: img.Layers[p.Layer].Digest.String()})
+		}
+		doc.Components = append(doc.Components, c)
+		deps = append(deps, c.BOMRef)
+	}
+
+	doc.Dependencies = []cdxDependency{{Ref: root.BOMRef, DependsOn: deps}}
+	return doc
+}
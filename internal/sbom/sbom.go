@@ -0,0 +1,119 @@
+// Package sbom renders an image's package inventory as a software bill of
+// materials in SPDX or CycloneDX JSON. Output depends only on its inputs, so
+// documents for the same image and timestamp are byte-identical.
+package sbom
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+// Formats lists the supported document formats.
+var Formats = []string{"spdx-json", "cyclonedx-json"}
+
+// Image describes the image an SBOM is for.
+type Image struct {
+	Reference string
+	Digest    v1.Hash // manifest digest
+	Layers    []Layer
+}
+
+// Layer is one layer of the image, in order.
+type Layer struct {
+	Digest  v1.Hash
+	Command string // Dockerfile step that created it, if recorded
+}
+
+// Write renders the SBOM for img and the inventory scanned from it to w in
+// format f. created is recorded as the document's creation time.
+func Write(w io.Writer, f string, img Image, inv *inventory.Inventory, created time.Time) error {
+	var doc any
+	switch f {
+	case "spdx-json":
+		doc = spdxDocument(img, inv, created)
+	case "cyclonedx-json":
+		doc = cyclonedxDocument(img, inv, created)
+	default:
+		return fmt.Errorf("unknown SBOM format %q: want one of %s", f, strings.Join(Formats, ", "))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(doc)
+}
+
+// packageIDs returns a stable identifier for each of pkgs, unique within an
+// image. Packages recorded twice with the same type, name, version, arch and
+// path, as when two layers install the same package, get a "-2", "-3", ...
+// suffix in order.
+func packageIDs(pkgs []inventory.Package) []string {
+	ids := make([]string, len(pkgs))
+	seen := map[string]int{}
+	for i, p := range pkgs {
+		sum := sha256.Sum256([]byte(strings.Join([]string{p.Type, p.Name, p.Version, p.Arch, p.Path}, "\x00")))
+		id := p.Type + "-" + hex.EncodeToString(sum[:8])
+		seen[id]++
+		if n := seen[id]; n > 1 {
+			id += "-" + strconv.Itoa(n)
+		}
+		ids[i] = id
+	}
+	return ids
+}
+
+// imagePURL returns the pkg:oci package URL of img, or "" when its reference
+// does not name a registry repository (OCI layouts and archives).
+func imagePURL(img Image) string {
+	ref, err := name.ParseReference(img.Reference)
+	if err != nil {
+		return ""
+	}
+	repo := ref.Context()
+	base := repo.RepositoryStr()
+	if i := strings.LastIndex(base, "/"); i >= 0 {
+		base = base[i+1:]
+	}
+	purl := "pkg:oci/" + base + "@" + strings.ReplaceAll(img.Digest.String(), ":", "%3A") +
+		"?repository_url=" + repo.Name()
+	if tag, ok := ref.(name.Tag); ok {
+		purl += "&tag=" + tag.TagStr()
+	}
+	return purl
+}
+
+// toolName is how documents name their creator.
+func toolName() string {
+	return "imgutil-" + version.Version
+}
+
+// namespaceURL is the RFC 4122 name space for URLs.
+var namespaceURL = [16]byte{0x6b, 0xa7, 0xb8, 0x11, 0x9d, 0xad, 0x11, 0xd1, 0x80, 0xb4, 0x00, 0xc0, 0x4f, 0xd4, 0x30, 0xc8}
+
+// uuid5 returns the name-based (SHA-1) UUID of s in the URL name space, so
+// the same image always gets the same serial number.
+func uuid5(s string) string {
+	h := sha1.New()
+	h.Write(namespaceURL[:])
+	h.Write([]byte(s))
+	u := h.Sum(nil)[:16]
+	u[6] = u[6]&0x0f | 0x50
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
+
+// timestamp formats t as the UTC, second-precision time both specs expect.
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
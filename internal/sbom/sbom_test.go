@@ -0,0 +1,213 @@
+package sbom_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+	"github.com/thisisnotashwin/imgutil/internal/sbom"
+)
+
+var created = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+func testImage(t *testing.T) (sbom.Image, *inventory.Inventory) {
+	t.Helper()
+	hash := func(c byte) v1.Hash {
+		return v1.Hash{Algorithm: "sha256", Hex: strings.Repeat(string(c), 64)}
+	}
+	img := sbom.Image{
+		Reference: "registry.example.com/team/app:v1",
+		Digest:    hash('a'),
+		Layers: []sbom.Layer{
+			{Digest: hash('b'), Command: "ADD rootfs.tar /"},
+			{Digest: hash('c'), Command: "RUN apt-get install -y curl"},
+		},
+	}
+	inv := &inventory.Inventory{
+		OS: &inventory.OSRelease{ID: "debian", VersionID: "12", PrettyName: "Debian GNU/Linux 12 (bookworm)"},
+		Packages: []inventory.Package{
+			{Type: "deb", Name: "curl", Version: "7.88.1-10", Arch: "amd64", Path: "/var/lib/dpkg/status", Layer: 1},
+			{Type: "deb", Name: "libc6", Version: "2.36-9", Arch: "amd64", Path: "/var/lib/dpkg/status", Layer: 0},
+		},
+	}
+	return img, inv
+}
+
+func write(t *testing.T, f string) []byte {
+	t.Helper()
+	img, inv := testImage(t)
+	var buf bytes.Buffer
+	if err := sbom.Write(&buf, f, img, inv, created); err != nil {
+		t.Fatalf("Write(%s): %v", f, err)
+	}
+	return buf.Bytes()
+}
+
+func TestWrite_Deterministic(t *testing.T) {
+	for _, f := range sbom.Formats {
+		if a, b := write(t, f), write(t, f); !bytes.Equal(a, b) {
+			t.Errorf("%s output differs between runs:\n%s\n---\n%s", f, a, b)
+		}
+	}
+}
+
+func TestWrite_SPDX(t *testing.T) {
+	var doc struct {
+		SPDXVersion  string `json:"spdxVersion"`
+		CreationInfo struct {
+			Created string `json:"created"`
+		} `json:"creationInfo"`
+		Packages []struct {
+			SPDXID       string `json:"SPDXID"`
+			Name         string `json:"name"`
+			ExternalRefs []struct {
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []struct {
+			From string `json:"spdxElementId"`
+			Type string `json:"relationshipType"`
+			To   string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	raw := write(t, "spdx-json")
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, raw)
+	}
+	if doc.SPDXVersion != "SPDX-2.3" || doc.CreationInfo.Created != "2024-01-02T03:04:05Z" {
+		t.Errorf("got version %q created %q", doc.SPDXVersion, doc.CreationInfo.Created)
+	}
+
+	purls := map[string]string{}
+	ids := map[string]string{}
+	for _, p := range doc.Packages {
+		ids[p.Name] = p.SPDXID
+		if len(p.ExternalRefs) > 0 {
+			purls[p.Name] = p.ExternalRefs[0].ReferenceLocator
+		}
+	}
+	if got, want := purls["registry.example.com/team/app:v1"], "pkg:oci/app@sha256%3A"+strings.Repeat("a", 64)+"?repository_url=registry.example.com/team/app&tag=v1"; got != want {
+		t.Errorf("image purl = %q, want %q", got, want)
+	}
+	if got, want := purls["curl"], "pkg:deb/debian/curl@7.88.1-10?arch=amd64&distro=debian-12"; got != want {
+		t.Errorf("curl purl = %q, want %q", got, want)
+	}
+
+	rels := map[string]bool{}
+	for _, r := range doc.Relationships {
+		rels[r.From+" "+r.Type+" "+r.To] = true
+	}
+	for _, want := range []string{
+		"SPDXRef-DOCUMENT DESCRIBES SPDXRef-Image",
+		"SPDXRef-Image CONTAINS SPDXRef-Layer-1",
+		"SPDXRef-Layer-1 CONTAINS " + ids["curl"],
+		"SPDXRef-Layer-0 CONTAINS " + ids["libc6"],
+	} {
+		if !rels[want] {
+			t.Errorf("missing relationship %q", want)
+		}
+	}
+}
+
+func TestWrite_CycloneDX(t *testing.T) {
+	var doc struct {
+		BOMFormat    string `json:"bomFormat"`
+		SpecVersion  string `json:"specVersion"`
+		SerialNumber string `json:"serialNumber"`
+		Metadata     struct {
+			Component struct {
+				Type   string `json:"type"`
+				Hashes []struct {
+					Content string `json:"content"`
+				} `json:"hashes"`
+			} `json:"component"`
+		} `json:"metadata"`
+		Components []struct {
+			Name       string `json:"name"`
+			PURL       string `json:"purl"`
+			Properties []struct {
+				Name  string `json:"name"`
+				Value string `json:"value"`
+			} `json:"properties"`
+		} `json:"components"`
+	}
+	raw := write(t, "cyclonedx-json")
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, raw)
+	}
+	if doc.BOMFormat != "CycloneDX" || doc.SpecVersion != "1.5" || !strings.HasPrefix(doc.SerialNumber, "urn:uuid:") {
+		t.Errorf("got format %q spec %q serial %q", doc.BOMFormat, doc.SpecVersion, doc.SerialNumber)
+	}
+	if c := doc.Metadata.Component; c.Type != "container" || len(c.Hashes) != 1 || c.Hashes[0].Content != strings.Repeat("a", 64) {
+		t.Errorf("got root component %+v, want container with the image digest", c)
+	}
+
+	var found bool
+	for _, c := range doc.Components {
+		if c.Name != "curl" {
+			continue
+		}
+		found = true
+		props := map[string]string{}
+		for _, p := range c.Properties {
+			props[p.Name] = p.Value
+		}
+		if props["imgutil:layer:digest"] != "sha256:"+strings.Repeat("c", 64) {
+			t.Errorf("curl layer digest = %q, want the second layer", props["imgutil:layer:digest"])
+		}
+	}
+	if !found {
+		t.Errorf("curl missing from components: %s", raw)
+	}
+}
+
+func TestWrite_UnknownFormat(t *testing.T) {
+	img, inv := testImage(t)
+	if err := sbom.Write(&bytes.Buffer{}, "spdx-tag-value", img, inv, created); err == nil {
+		t.Fatal("expected an error for an unknown format")
+	}
+}
+
+func TestWrite_DuplicatePackages(t *testing.T) {
+	img, inv := testImage(t)
+	inv.Packages = append(inv.Packages, inv.Packages[0])
+
+	for _, f := range sbom.Formats {
+		var buf bytes.Buffer
+		if err := sbom.Write(&buf, f, img, inv, created); err != nil {
+			t.Fatalf("Write(%s): %v", f, err)
+		}
+		var doc struct {
+			Packages []struct {
+				SPDXID string `json:"SPDXID"`
+			} `json:"packages"`
+			Components []struct {
+				BOMRef string `json:"bom-ref"`
+			} `json:"components"`
+		}
+		if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+			t.Fatalf("%s: invalid JSON: %v", f, err)
+		}
+		var ids []string
+		for _, p := range doc.Packages {
+			ids = append(ids, p.SPDXID)
+		}
+		for _, c := range doc.Components {
+			ids = append(ids, c.BOMRef)
+		}
+		seen := map[string]bool{}
+		for _, id := range ids {
+			if seen[id] {
+				t.Errorf("%s: duplicate identifier %s", f, id)
+			}
+			seen[id] = true
+		}
+		if len(seen) < len(inv.Packages) {
+			t.Errorf("%s: got %d identifiers, want at least %d", f, len(seen), len(inv.Packages))
+		}
+	}
+}
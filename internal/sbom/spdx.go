@@ -0,0 +1,149 @@
+package sbom
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+)
+
+// SPDX 2.3 JSON (https://spdx.github.io/spdx-spec/v2.3/). The image, each
+// layer and each package are SPDX packages; relationships record that the
+// image contains its layers and each layer the packages it installed.
+
+type spdxDoc struct {
+	SPDXVersion       string             `json:"spdxVersion"`
+	DataLicense       string             `json:"dataLicense"`
+	SPDXID            string             `json:"SPDXID"`
+	Name              string             `json:"name"`
+	DocumentNamespace string             `json:"documentNamespace"`
+	CreationInfo      spdxCreationInfo   `json:"creationInfo"`
+	Packages          []spdxPackage      `json:"packages"`
+	Relationships     []spdxRelationship `json:"relationships"`
+}
+
+type spdxCreationInfo struct {
+	Created  string   `json:"created"`
+	Creators []string `json:"creators"`
+}
+
+type spdxPackage struct {
+	Name                  string            `json:"name"`
+	SPDXID                string            `json:"SPDXID"`
+	VersionInfo           string            `json:"versionInfo,omitempty"`
+	DownloadLocation      string            `json:"downloadLocation"`
+	FilesAnalyzed         bool              `json:"filesAnalyzed"`
+	Checksums             []spdxChecksum    `json:"checksums,omitempty"`
+	SourceInfo            string            `json:"sourceInfo,omitempty"`
+	Comment               string            `json:"comment,omitempty"`
+	ExternalRefs          []spdxExternalRef `json:"externalRefs,omitempty"`
+	PrimaryPackagePurpose string            `json:"primaryPackagePurpose,omitempty"`
+}
+
+type spdxChecksum struct {
+	Algorithm     string `json:"algorithm"`
+	ChecksumValue string `json:"checksumValue"`
+}
+
+type spdxExternalRef struct {
+	ReferenceCategory string `json:"referenceCategory"`
+	ReferenceType     string `json:"referenceType"`
+	ReferenceLocator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	SPDXElementID      string `json:"spdxElementId"`
+	RelationshipType   string `json:"relationshipType"`
+	RelatedSPDXElement string `json:"relatedSpdxElement"`
+}
+
+const (
+	spdxNoAssertion = "NOASSERTION"
+	spdxImageID     = "SPDXRef-Image"
+)
+
+func spdxDocument(img Image, inv *inventory.Inventory, created time.Time) spdxDoc {
+	doc := spdxDoc{
+		SPDXVersion:       "SPDX-2.3",
+		DataLicense:       "CC0-1.0",
+		SPDXID:            "SPDXRef-DOCUMENT",
+		Name:              img.Reference,
+		DocumentNamespace: "https://github.com/thisisnotashwin/imgutil/sbom/" + img.Digest.Hex,
+		CreationInfo: spdxCreationInfo{
+			Created:  timestamp(created),
+			Creators: []string{"Tool: " + toolName()},
+		},
+	}
+	relate := func(from, typ, to string) {
+		doc.Relationships = append(doc.Relationships, spdxRelationship{from, typ, to})
+	}
+
+	root := spdxPackage{
+		Name:                  img.Reference,
+		SPDXID:                spdxImageID,
+		VersionInfo:           img.Digest.String(),
+		DownloadLocation:      spdxNoAssertion,
+		Checksums:             []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: img.Digest.Hex}},
+		PrimaryPackagePurpose: "CONTAINER",
+	}
+	if purl := imagePURL(img); purl != "" {
+		root.ExternalRefs = []spdxExternalRef{spdxPURL(purl)}
+	}
+	doc.Packages = append(doc.Packages, root)
+	relate(doc.SPDXID, "DESCRIBES", spdxImageID)
+
+	for i, l := range img.Layers {
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:                  l.Digest.String(),
+			SPDXID:                spdxLayerID(i),
+			DownloadLocation:      spdxNoAssertion,
+			Checksums:             []spdxChecksum{{Algorithm: "SHA256", ChecksumValue: l.Digest.Hex}},
+			Comment:               l.Command,
+			PrimaryPackagePurpose: "ARCHIVE",
+		})
+		relate(spdxImageID, "CONTAINS", spdxLayerID(i))
+	}
+
+	if inv.OS != nil && inv.OS.ID != "" {
+		doc.Packages = append(doc.Packages, spdxPackage{
+			Name:                  inv.OS.ID,
+			SPDXID:                "SPDXRef-OperatingSystem",
+			VersionInfo:           inv.OS.VersionID,
+			DownloadLocation:      spdxNoAssertion,
+			Comment:               inv.OS.PrettyName,
+			PrimaryPackagePurpose: "OPERATING-SYSTEM",
+		})
+		relate(spdxImageID, "CONTAINS", "SPDXRef-OperatingSystem")
+	}
+
+	ids := packageIDs(inv.Packages)
+	for i, p := range inv.Packages {
+		id := "SPDXRef-Package-" + ids[i]
+		pkg := spdxPackage{
+			Name:                  p.Name,
+			SPDXID:                id,
+			VersionInfo:           p.Version,
+			DownloadLocation:      spdxNoAssertion,
+			SourceInfo:            "recorded in " + p.Path,
+			PrimaryPackagePurpose: "LIBRARY",
+		}
+		if purl := p.PURL(inv.OS); purl != "" {
+			pkg.ExternalRefs = []spdxExternalRef{spdxPURL(purl)}
+		}
+		doc.Packages = append(doc.Packages, pkg)
+		if p.Layer < len(img.Layers) {
+			relate(spdxLayerID(p.Layer), "CONTAINS", id)
+		} else {
+			relate(spdxImageID, "CONTAINS", id)
+		}
+	}
+	return doc
+}
+
+func spdxLayerID(i int) string {
+	return fmt.Sprintf("SPDXRef-Layer-%d", i)
+}
+
+func spdxPURL(purl string) spdxExternalRef {
+	return spdxExternalRef{ReferenceCategory: "PACKAGE-MANAGER", ReferenceType: "purl", ReferenceLocator: purl}
+}
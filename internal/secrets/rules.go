@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"math"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// rule recognises one kind of secret. The first capture group of re, if it
+// has one, is the secret itself and is redacted in snippets.
+type rule struct {
+	ID          string
+	Description string
+	re          *regexp.Regexp
+	// paths, if set, restricts the rule to files it accepts. It is not
+	// consulted for environment variables and history.
+	paths func(p string) bool
+	// minEntropy, if set, is the Shannon entropy in bits per character the
+	// secret must reach, to skip placeholders like "changeme".
+	minEntropy float64
+}
+
+var rules = []rule{
+	{
+		ID:          "private-key",
+		Description: "Private key",
+		re:          regexp.MustCompile(`-----BEGIN (?:RSA |DSA |EC |OPENSSH |ENCRYPTED |PGP )?PRIVATE KEY(?: BLOCK)?-----`),
+	},
+	{
+		ID:          "aws-access-key-id",
+		Description: "AWS access key ID",
+		re:          regexp.MustCompile(`\b((?:AKIA|ASIA)[0-9A-Z]{16})\b`),
+	},
+	{
+		ID:          "aws-secret-access-key",
+		Description: "AWS secret access key",
+		re:          regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`),
+	},
+	{
+		ID:          "gcp-service-account-key",
+		Description: "Google Cloud service account key",
+		re:          regexp.MustCompile(`"private_key_id"\s*:\s*"([0-9a-f]{40})"`),
+	},
+	{
+		ID:          "azure-storage-key",
+		Description: "Azure storage account key",
+		re:          regexp.MustCompile(`AccountKey=([A-Za-z0-9+/]{86}==)`),
+	},
+	{
+		ID:          "github-token",
+		Description: "GitHub token",
+		re:          regexp.MustCompile(`\b((?:ghp|gho|ghu|ghs|ghr)_[A-Za-z0-9]{36}|github_pat_[A-Za-z0-9_]{82})\b`),
+	},
+	{
+		ID:          "gitlab-token",
+		Description: "GitLab personal access token",
+		re:          regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20})\b`),
+	},
+	{
+		ID:          "slack-token",
+		Description: "Slack token",
+		re:          regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})`),
+	},
+	{
+		ID:          "stripe-secret-key",
+		Description: "Stripe secret key",
+		re:          regexp.MustCompile(`\b((?:sk|rk)_live_[A-Za-z0-9]{24,})\b`),
+	},
+	{
+		ID:          "npm-token",
+		Description: "npm access token",
+		re:          regexp.MustCompile(`\b(npm_[A-Za-z0-9]{36})\b`),
+	},
+	{
+		ID:          "npmrc-auth",
+		Description: "Registry credentials in .npmrc",
+		re:          regexp.MustCompile(`(?m)(?:_authToken|_auth|_password)\s*=\s*["']?([^\s"']+)`),
+		paths:       func(p string) bool { return path.Base(p) == ".npmrc" },
+	},
+	{
+		ID:          "docker-config-auth",
+		Description: "Registry credentials in Docker config.json",
+		re:          regexp.MustCompile(`"(?:auth|identitytoken)"\s*:\s*"([^"]+)"`),
+		paths: func(p string) bool {
+			return strings.HasSuffix(p, "/.docker/config.json") || strings.HasSuffix(p, "/.dockercfg")
+		},
+	},
+	{
+		ID:          "generic-secret",
+		Description: "High-entropy value assigned to a secret-like name",
+		re:          regexp.MustCompile(`(?i)(?:password|passwd|secret|token|api_?key|access_?key|auth_?key|client_?secret)[A-Za-z_]*["']?\s*[:=]\s*["']?([A-Za-z0-9+/=_.~-]{16,})`),
+		minEntropy:  3.5,
+	},
+}
+
+// secretName matches environment variable and build argument names that
+// suggest a secret value.
+var secretName = regexp.MustCompile(`(?i)(?:^|_)(?:password|passwd|pwd|secret|token|api_?key|access_?key|private_?key|credentials?)(?:$|_)`)
+
+// notSecretSuffixes mark names of variables holding where a secret is, not
+// the secret, as in PASSWORD_FILE.
+var notSecretSuffixes = []string{"_FILE", "_PATH", "_DIR", "_URL"}
+
+// entropy returns the Shannon entropy of s in bits per character.
+func entropy(s string) float64 {
+	counts := map[rune]int{}
+	for _, r := range s {
+		counts[r]++
+	}
+	var h float64
+	n := float64(len([]rune(s)))
+	for _, c := range counts {
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
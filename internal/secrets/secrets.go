@@ -0,0 +1,330 @@
+// Package secrets finds credentials left in an image: in the files of every
+// layer, including files later layers delete, and in the image's environment
+// and build history, where build arguments are recorded.
+package secrets
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/thisisnotashwin/imgutil/internal/layer"
+)
+
+// Finding is one secret found in an image.
+type Finding struct {
+	RuleID      string
+	Description string
+	Source      string // "file", "env" or "history"
+	// Location says where to look: "/root/.npmrc:3", "ENV NPM_TOKEN" or
+	// "history step 4".
+	Location string
+	Path     string // file path, for file findings
+	Line     int    // 1-based line in Path
+	// Layer is the index of the layer holding the secret, or -1 for the
+	// environment and history steps that produced no layer.
+	Layer int
+	// Removed reports that the file is not in the final filesystem: a later
+	// layer deleted or replaced it, but it can still be read from its layer.
+	Removed bool
+	Snippet string // the matching line, with the secret redacted
+}
+
+// maxFileSize bounds the files read; larger ones are almost always binaries
+// or data.
+const maxFileSize = 8 << 20
+
+// ScanLayers streams every layer once and returns the secrets in their files,
+// in layer order. Binary files are skipped.
+func ScanLayers(layers []v1.Layer) ([]Finding, error) {
+	var findings []Finding
+	// present maps each path in the filesystem to the layer that wrote it.
+	present := map[string]int{}
+
+	err := layer.Merge(layers, layer.Merger{
+		Entry: func(i int, hdr *tar.Header, r io.Reader) error {
+			if hdr.Typeflag != tar.TypeReg || hdr.Size > maxFileSize {
+				return nil
+			}
+			b, err := io.ReadAll(r)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", hdr.Name, err)
+			}
+			if isBinary(b) {
+				return nil
+			}
+			for _, m := range scanText(string(b), hdr.Name) {
+				findings = append(findings, Finding{
+					RuleID:      m.rule.ID,
+					Description: m.rule.Description,
+					Source:      "file",
+					Location:    fmt.Sprintf("%s:%d", hdr.Name, m.line),
+					Path:        hdr.Name,
+					Line:        m.line,
+					Layer:       i,
+					Snippet:     m.snippet,
+				})
+			}
+			return nil
+		},
+		Remove: func(_ int, p string) { delete(present, p) },
+		Add:    func(i int, hdr *tar.Header) { present[hdr.Name] = i },
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for j := range findings {
+		f := &findings[j]
+		if at, ok := present[f.Path]; !ok || at != f.Layer {
+			f.Removed = true
+		}
+	}
+	return findings, nil
+}
+
+// ScanConfig returns the secrets in cfg's environment variables and in the
+// commands and build arguments its history records.
+func ScanConfig(cfg *v1.ConfigFile) []Finding {
+	var findings []Finding
+	for _, e := range cfg.Config.Env {
+		name, _, _ := strings.Cut(e, "=")
+		for _, m := range scanCommand(e) {
+			findings = append(findings, Finding{
+				RuleID:      m.rule.ID,
+				Description: m.rule.Description,
+				Source:      "env",
+				Location:    "ENV " + name,
+				Layer:       -1,
+				Snippet:     m.snippet,
+			})
+		}
+	}
+
+	next := 0
+	for i, h := range cfg.History {
+		l := -1
+		if !h.EmptyLayer {
+			l = next
+			next++
+		}
+		for _, m := range scanCommand(h.CreatedBy) {
+			findings = append(findings, Finding{
+				RuleID:      m.rule.ID,
+				Description: m.rule.Description,
+				Source:      "history",
+				Location:    fmt.Sprintf("history step %d", i+1),
+				Layer:       l,
+				Snippet:     m.snippet,
+			})
+		}
+	}
+	return findings
+}
+
+// secretVariable reports values of variables whose name suggests a secret,
+// for environment variables and build arguments no other rule recognises.
+var secretVariable = &rule{ID: "secret-variable", Description: "Value of a secret-like variable"}
+
+type match struct {
+	rule    *rule
+	line    int
+	secret  string
+	snippet string
+}
+
+// scanCommand scans an environment variable or a history command: the
+// content rules, then each NAME=value word whose value they did not already
+// report, such as the "|1 NPM_TOKEN=..." prefix recording a build argument.
+func scanCommand(s string) []match {
+	matches := scanText(s, "")
+words:
+	for _, word := range strings.Fields(s) {
+		name, value, ok := strings.Cut(word, "=")
+		value = strings.Trim(value, `"'`)
+		if !ok || value == "" || strings.HasPrefix(value, "$") || !isSecretName(name) {
+			continue
+		}
+		for _, m := range matches {
+			if m.secret != "" && strings.Contains(value, m.secret) {
+				continue words
+			}
+		}
+		matches = append(matches, match{
+			rule:    secretVariable,
+			line:    1,
+			secret:  value,
+			snippet: name + "=" + redact(value),
+		})
+	}
+	return matches
+}
+
+// scanText applies the rules to text, the contents of the file at p, or of
+// no file if p is "". Matches overlapping an earlier one are dropped, so a
+// value found by a specific rule is not reported again as generic.
+func scanText(text, p string) []match {
+	var (
+		matches []match
+		spans   [][2]int
+	)
+	for i := range rules {
+		r := &rules[i]
+		if r.paths != nil && (p == "" || !r.paths(p)) {
+			continue
+		}
+	next:
+		for _, loc := range r.re.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[0], loc[1]
+			secretStart, secretEnd := -1, -1
+			if len(loc) >= 4 && loc[2] >= 0 {
+				secretStart, secretEnd = loc[2], loc[3]
+			}
+			var secret string
+			if secretStart >= 0 {
+				secret = text[secretStart:secretEnd]
+			}
+			if r.minEntropy > 0 && entropy(secret) < r.minEntropy {
+				continue
+			}
+			for _, s := range spans {
+				if start < s[1] && s[0] < end {
+					continue next
+				}
+			}
+			spans = append(spans, [2]int{start, end})
+			matches = append(matches, match{
+				rule:    r,
+				line:    strings.Count(text[:start], "\n") + 1,
+				secret:  secret,
+				snippet: snippet(text, start, end, secretStart, secretEnd),
+			})
+		}
+	}
+	return matches
+}
+
+// maxSnippet bounds the length of a snippet.
+const maxSnippet = 120
+
+// snippet returns the line holding the match at text[start:end] with every
+// secret on it redacted: the match's own secret at
+// text[secretStart:secretEnd], if any, and whatever secretSpans finds.
+func snippet(text string, start, end, secretStart, secretEnd int) string {
+	lineStart := strings.LastIndexByte(text[:start], '\n') + 1
+	lineEnd := len(text)
+	if i := strings.IndexByte(text[end:], '\n'); i >= 0 {
+		lineEnd = end + i
+	}
+	line := text[lineStart:lineEnd]
+	at := start - lineStart
+	spans := secretSpans(line)
+	if secretStart >= 0 {
+		spans = append(spans, [2]int{secretStart - lineStart, secretEnd - lineStart})
+		at = secretStart - lineStart
+	}
+	line, at = redactSpans(line, spans, at)
+	line = strings.Join(strings.Fields(line), " ")
+
+	if len(line) > maxSnippet {
+		from := max(0, min(at-40, len(line)-maxSnippet))
+		line = line[from : from+maxSnippet]
+		if from > 0 {
+			line = "..." + line
+		}
+		line += "..."
+	}
+	return strings.ToValidUTF8(line, "")
+}
+
+// assignment matches NAME=value and name: value, with the value optionally
+// quoted.
+var assignment = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_.-]*)["']?\s*[:=]\s*("[^"]*"|'[^']*'|[^\s"']+)`)
+
+// secretSpans returns the spans of line holding secrets: the secrets every
+// rule matches, wherever the rule applies, and the values assigned to
+// secret-like names. Rules without a capture group mark where a secret
+// starts, like a private key header, and are not themselves redacted.
+func secretSpans(line string) [][2]int {
+	var spans [][2]int
+	for i := range rules {
+		for _, loc := range rules[i].re.FindAllStringSubmatchIndex(line, -1) {
+			if len(loc) >= 4 && loc[2] >= 0 {
+				spans = append(spans, [2]int{loc[2], loc[3]})
+			}
+		}
+	}
+	for _, loc := range assignment.FindAllStringSubmatchIndex(line, -1) {
+		value := strings.Trim(line[loc[4]:loc[5]], `"'`)
+		if value != "" && !strings.HasPrefix(value, "$") && isSecretName(line[loc[2]:loc[3]]) {
+			spans = append(spans, [2]int{loc[4], loc[5]})
+		}
+	}
+	return spans
+}
+
+// redactSpans redacts the given spans of s, merging overlapping ones, and
+// returns the result with at moved to the same place in it.
+func redactSpans(s string, spans [][2]int, at int) (string, int) {
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	var (
+		b      strings.Builder
+		pos    int
+		newAt  = at
+		merged [][2]int
+	)
+	for _, sp := range spans {
+		if n := len(merged); n > 0 && sp[0] <= merged[n-1][1] {
+			merged[n-1][1] = max(merged[n-1][1], sp[1])
+			continue
+		}
+		merged = append(merged, sp)
+	}
+	for _, sp := range merged {
+		b.WriteString(s[pos:sp[0]])
+		r := redact(s[sp[0]:sp[1]])
+		switch {
+		case at >= sp[1]:
+			newAt += len(r) - (sp[1] - sp[0])
+		case at >= sp[0]:
+			newAt = b.Len()
+		}
+		b.WriteString(r)
+		pos = sp[1]
+	}
+	b.WriteString(s[pos:])
+	return b.String(), newAt
+}
+
+// isSecretName reports whether a variable called name likely holds a
+// secret, rather than where to find one, as PASSWORD_FILE does.
+func isSecretName(name string) bool {
+	if !secretName.MatchString(name) {
+		return false
+	}
+	for _, suffix := range notSecretSuffixes {
+		if strings.HasSuffix(strings.ToUpper(name), suffix) {
+			return false
+		}
+	}
+	return true
+}
+
+// redact hides a secret, keeping a short prefix of long ones so findings can
+// be told apart.
+func redact(s string) string {
+	if len(s) < 16 {
+		return "********"
+	}
+	return s[:4] + "********"
+}
+
+// isBinary reports whether b looks like binary data rather than text.
+func isBinary(b []byte) bool {
+	return bytes.IndexByte(b[:min(len(b), 8000)], 0) >= 0
+}
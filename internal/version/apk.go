@@ -0,0 +1,95 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// apkSuffixes ranks the suffixes apk allows after the numeric version:
+// pre-releases sort before the plain version, the rest after it.
+var apkSuffixes = map[string]int{
+	"alpha": -4, "beta": -3, "pre": -2, "rc": -1,
+	"cvs": 1, "svn": 2, "git": 3, "hg": 4, "p": 5,
+}
+
+type apkVersion struct {
+	nums     []string
+	letter   byte
+	suffixes [][2]int // rank and number
+	revision int
+}
+
+// CompareApk returns -1, 0 or 1 as Alpine package version a sorts before,
+// equal to or after b. Versions are dotted numbers, an optional letter,
+// suffixes such as "_rc1" or "_p2", and a "-rN" package revision, as in
+// "1.2.4a_p1-r2". Parsing is lenient: anything unrecognised is ignored.
+func CompareApk(a, b string) int {
+	va, vb := parseApk(a), parseApk(b)
+	for i := 0; i < len(va.nums) && i < len(vb.nums); i++ {
+		if c := compareNumeric(va.nums[i], vb.nums[i]); c != 0 {
+			return c
+		}
+	}
+	// 1.2.1 is newer than 1.2, whatever follows.
+	if c := compareInt(len(va.nums), len(vb.nums)); c != 0 {
+		return c
+	}
+	if c := compareInt(int(va.letter), int(vb.letter)); c != 0 {
+		return c
+	}
+	for i := 0; i < len(va.suffixes) || i < len(vb.suffixes); i++ {
+		var sa, sb [2]int
+		if i < len(va.suffixes) {
+			sa = va.suffixes[i]
+		}
+		if i < len(vb.suffixes) {
+			sb = vb.suffixes[i]
+		}
+		if c := compareInt(sa[0], sb[0]); c != 0 {
+			return c
+		}
+		if c := compareInt(sa[1], sb[1]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(va.revision, vb.revision)
+}
+
+func parseApk(s string) apkVersion {
+	var v apkVersion
+	if i := strings.LastIndex(s, "-r"); i >= 0 {
+		if n, err := strconv.Atoi(s[i+2:]); err == nil {
+			v.revision, s = n, s[:i]
+		}
+	}
+	// A "~<hash>" after the suffixes names a commit and is not ordered.
+	s, _, _ = strings.Cut(s, "~")
+
+	for {
+		var n string
+		n, s = digits(s)
+		if n == "" {
+			break
+		}
+		v.nums = append(v.nums, n)
+		if len(s) < 2 || s[0] != '.' || !isDigit(s[1]) {
+			break
+		}
+		s = s[1:]
+	}
+	if s != "" && 'a' <= s[0] && s[0] <= 'z' {
+		v.letter, s = s[0], s[1:]
+	}
+	for strings.HasPrefix(s, "_") {
+		var name, n string
+		name, s = letters(s[1:])
+		n, s = digits(s)
+		rank, ok := apkSuffixes[name]
+		if !ok {
+			break
+		}
+		num, _ := strconv.Atoi(n)
+		v.suffixes = append(v.suffixes, [2]int{rank, num})
+	}
+	return v
+}
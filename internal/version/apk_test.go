@@ -0,0 +1,21 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+func TestCompareApk(t *testing.T) {
+	checkCompare(t, version.CompareApk, []compareCase{
+		{"1.2.4-r2", "1.2.4-r2", 0},
+		{"1.2.4-r2", "1.2.4-r10", -1},
+		{"1.2.10", "1.2.9", 1},
+		{"1.2", "1.2.1", -1},
+		{"1.2_rc1", "1.2", -1},
+		{"1.2_p1", "1.2-r5", 1},
+		{"1.2a", "1.2b", -1},
+		{"1.2a", "1.2", 1},
+		{"3.1.4_alpha1", "3.1.4_beta1", -1},
+	})
+}
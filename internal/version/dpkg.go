@@ -0,0 +1,95 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareDpkg returns -1, 0 or 1 as Debian package version a sorts before,
+// equal to or after b, following deb-version(7): an optional numeric epoch
+// ("1:"), then the upstream version and the revision after the last "-".
+// "~" sorts before anything, even the end of the version, so "1.0~rc1" is
+// older than "1.0".
+func CompareDpkg(a, b string) int {
+	ea, ua, ra := splitDpkg(a)
+	eb, ub, rb := splitDpkg(b)
+	if c := compareInt(ea, eb); c != 0 {
+		return c
+	}
+	if c := dpkgVerrevcmp(ua, ub); c != 0 {
+		return c
+	}
+	return dpkgVerrevcmp(ra, rb)
+}
+
+func splitDpkg(v string) (epoch int, upstream, revision string) {
+	if e, rest, ok := strings.Cut(v, ":"); ok {
+		if n, err := strconv.Atoi(e); err == nil {
+			epoch, v = n, rest
+		}
+	}
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		return epoch, v[:i], v[i+1:]
+	}
+	return epoch, v, ""
+}
+
+// dpkgVerrevcmp is dpkg's verrevcmp: alternating runs of non-digits, compared
+// character by character with letters before other symbols, and digits,
+// compared numerically.
+func dpkgVerrevcmp(a, b string) int {
+	for a != "" || b != "" {
+		for (a != "" && !isDigit(a[0])) || (b != "" && !isDigit(b[0])) {
+			ac, bc := dpkgOrder(a), dpkgOrder(b)
+			if ac != bc {
+				return compareInt(ac, bc)
+			}
+			a, b = a[1:], b[1:]
+		}
+		var na, nb string
+		na, a = digits(a)
+		nb, b = digits(b)
+		if c := compareNumeric(na, nb); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// dpkgOrder weights the first character of s: "~" lowest, then the end of
+// the string or a digit, then letters, then everything else.
+func dpkgOrder(s string) int {
+	switch {
+	case s == "" || isDigit(s[0]):
+		return 0
+	case s[0] == '~':
+		return -1
+	case isAlpha(s[0]):
+		return int(s[0])
+	default:
+		return int(s[0]) + 256
+	}
+}
+
+// digits splits the leading run of digits off s.
+func digits(s string) (run, rest string) {
+	i := 0
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
+
+// compareNumeric compares runs of digits of any length numerically. An empty
+// run counts as zero.
+func compareNumeric(a, b string) int {
+	a, b = strings.TrimLeft(a, "0"), strings.TrimLeft(b, "0")
+	if c := compareInt(len(a), len(b)); c != 0 {
+		return c
+	}
+	return strings.Compare(a, b)
+}
+
+func isDigit(c byte) bool { return '0' <= c && c <= '9' }
+
+func isAlpha(c byte) bool { return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' }
@@ -0,0 +1,39 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+type compareCase struct {
+	a, b string
+	want int
+}
+
+// checkCompare runs cmp over cases in both directions.
+func checkCompare(t *testing.T, cmp func(a, b string) int, cases []compareCase) {
+	t.Helper()
+	for _, tc := range cases {
+		if got := cmp(tc.a, tc.b); got != tc.want {
+			t.Errorf("compare(%s, %s) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := cmp(tc.b, tc.a); got != -tc.want {
+			t.Errorf("compare(%s, %s) = %d, want %d", tc.b, tc.a, got, -tc.want)
+		}
+	}
+}
+
+func TestCompareDpkg(t *testing.T) {
+	checkCompare(t, version.CompareDpkg, []compareCase{
+		{"2.36-9", "2.36-9", 0},
+		{"2.36-9", "2.36-10", -1},
+		{"1:1.0-1", "2.0-1", 1},
+		{"1.0~rc1-1", "1.0-1", -1},
+		{"1.0~~", "1.0~", -1},
+		{"1.0-1", "1.0-1+deb12u1", -1},
+		{"1.0a", "1.0+", -1},
+		{"1.2.10", "1.2.9", 1},
+		{"007", "7", 0},
+	})
+}
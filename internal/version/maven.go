@@ -0,0 +1,111 @@
+package version
+
+import (
+	"strings"
+)
+
+// mavenQualifiers ranks the well-known Maven qualifiers, including the
+// abbreviations in versions like "1.0a1"; "" is the release. Unknown
+// qualifiers sort after all of them, alphabetically.
+var mavenQualifiers = map[string]int{
+	"alpha": 0, "a": 0,
+	"beta": 1, "b": 1,
+	"milestone": 2, "m": 2,
+	"rc": 3, "cr": 3,
+	"snapshot": 4,
+	"":         5, "ga": 5, "final": 5, "release": 5,
+	"sp": 6,
+}
+
+// CompareMaven returns -1, 0 or 1 as Maven version a sorts before, equal to
+// or after b, following Maven's ComparableVersion closely enough for
+// advisory ranges: versions split into numbers and qualifiers at ".", "-"
+// and changes between digits and letters; numbers compare numerically and
+// sort after qualifiers; and pre-release qualifiers ("alpha", "beta",
+// "milestone", "rc", "snapshot") sort before the release, so "1.0-beta1" is
+// older than "1.0". Unlike Maven, "." and "-" are not distinguished.
+func CompareMaven(a, b string) int {
+	ta, tb := mavenTokens(a), mavenTokens(b)
+	for i := 0; i < len(ta) || i < len(tb); i++ {
+		var x, y string
+		if i < len(ta) {
+			x = ta[i]
+		}
+		if i < len(tb) {
+			y = tb[i]
+		}
+		if c := compareMavenItem(x, y); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+// mavenTokens splits v into items, dropping trailing zeros and release
+// qualifiers, so "1.0.0-final" is "1".
+func mavenTokens(v string) []string {
+	v = strings.ToLower(v)
+	var tokens []string
+	start := 0
+	flush := func(end int) {
+		tokens = append(tokens, v[start:end])
+		start = end
+	}
+	for i := 0; i < len(v); i++ {
+		switch {
+		case v[i] == '.' || v[i] == '-' || v[i] == '_':
+			flush(i)
+			start = i + 1
+		case i > start && isDigit(v[i]) != isDigit(v[i-1]):
+			flush(i)
+		}
+	}
+	flush(len(v))
+
+	for len(tokens) > 0 && isMavenRelease(tokens[len(tokens)-1]) {
+		tokens = tokens[:len(tokens)-1]
+	}
+	return tokens
+}
+
+// isMavenRelease reports whether t is a zero or a qualifier meaning the
+// release itself.
+func isMavenRelease(t string) bool {
+	if t != "" && isDigit(t[0]) {
+		return strings.Trim(t, "0") == ""
+	}
+	rank, ok := mavenQualifiers[t]
+	return ok && rank == mavenQualifiers[""]
+}
+
+// compareMavenItem compares two items, either of which may be "" for a
+// missing item: a missing number is 0 and a missing qualifier the release.
+func compareMavenItem(x, y string) int {
+	xNum := x != "" && isDigit(x[0])
+	yNum := y != "" && isDigit(y[0])
+	switch {
+	case xNum && yNum:
+		return compareNumeric(x, y)
+	case xNum:
+		if y == "" {
+			return compareNumeric(x, "0")
+		}
+		return 1
+	case yNum:
+		if x == "" {
+			return compareNumeric("0", y)
+		}
+		return -1
+	}
+	rx, okx := mavenQualifiers[x]
+	ry, oky := mavenQualifiers[y]
+	switch {
+	case okx && oky:
+		return compareInt(rx, ry)
+	case okx:
+		return -1
+	case oky:
+		return 1
+	}
+	return strings.Compare(x, y)
+}
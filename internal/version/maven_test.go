@@ -0,0 +1,29 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+func TestCompareMaven(t *testing.T) {
+	checkCompare(t, version.CompareMaven, []compareCase{
+		{"1.0", "1.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"1.0", "1.0-final", 0},
+		{"1.0-GA", "1.0", 0},
+		{"1.0-beta", "1.0", -1},
+		{"1.0-alpha1", "1.0-beta1", -1},
+		{"1.0-beta1", "1.0-rc1", -1},
+		{"1.0-rc1", "1.0", -1},
+		{"1.0-M1", "1.0-RC1", -1},
+		{"1.0-SNAPSHOT", "1.0", -1},
+		{"1.0-rc1", "1.0-SNAPSHOT", -1},
+		{"1.0-sp1", "1.0", 1},
+		{"1.0.1", "1.0", 1},
+		{"1.0.1", "1.0-sp1", 1},
+		{"2.10", "2.9", 1},
+		{"1.0a1", "1.0-alpha-1", 0},
+		{"1.0-foo", "1.0-sp", 1},
+	})
+}
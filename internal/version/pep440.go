@@ -0,0 +1,120 @@
+package version
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// PEP440 is a Python package version (https://peps.python.org/pep-0440/).
+type PEP440 struct {
+	Epoch   int
+	Release []string // numeric components, e.g. ["2", "0", "0"]
+	// Pre is the pre-release phase, 0 for "a", 1 for "b" and 2 for "rc",
+	// or -1 for none.
+	Pre, PreN int
+	Post      int // -1 for none
+	Dev       int // -1 for none
+	Local     string
+}
+
+// pep440Pattern is the lenient form accepted by pip, with the alternative
+// spellings normalised by ParsePEP440.
+var pep440Pattern = regexp.MustCompile(`^v?(?:(\d+)!)?(\d+(?:\.\d+)*)` +
+	`(?:[-_.]?(alpha|beta|preview|pre|rc|a|b|c)[-_.]?(\d+)?)?` +
+	`(?:-(\d+)|[-_.]?(post|rev|r)[-_.]?(\d+)?)?` +
+	`(?:[-_.]?(dev)[-_.]?(\d+)?)?` +
+	`(?:\+([a-z0-9]+(?:[-_.][a-z0-9]+)*))?$`)
+
+var pep440Phases = map[string]int{
+	"a": 0, "alpha": 0,
+	"b": 1, "beta": 1,
+	"rc": 2, "c": 2, "pre": 2, "preview": 2,
+}
+
+// ParsePEP440 parses s as a PEP 440 version, such as "2.0.0rc1",
+// "1.0.post2" or "1!2.0.dev3".
+func ParsePEP440(s string) (PEP440, error) {
+	m := pep440Pattern.FindStringSubmatch(strings.ToLower(strings.TrimSpace(s)))
+	if m == nil {
+		return PEP440{}, fmt.Errorf("invalid PEP 440 version %q", s)
+	}
+	num := func(s string) int {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return 0
+		}
+		return n
+	}
+
+	v := PEP440{Epoch: num(m[1]), Release: strings.Split(m[2], "."), Pre: -1, Post: -1, Dev: -1, Local: m[10]}
+	if m[3] != "" {
+		v.Pre, v.PreN = pep440Phases[m[3]], num(m[4])
+	}
+	switch {
+	case m[5] != "":
+		v.Post = num(m[5])
+	case m[6] != "":
+		v.Post = num(m[7])
+	}
+	if m[8] != "" {
+		v.Dev = num(m[9])
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 as v sorts before, equal to or after o. Trailing
+// zeros in the release are insignificant, dev releases sort before
+// pre-releases, which sort before the final release, and post releases sort
+// after it.
+func (v PEP440) Compare(o PEP440) int {
+	if c := compareInt(v.Epoch, o.Epoch); c != 0 {
+		return c
+	}
+	for i := 0; i < len(v.Release) || i < len(o.Release); i++ {
+		a, b := "0", "0"
+		if i < len(v.Release) {
+			a = v.Release[i]
+		}
+		if i < len(o.Release) {
+			b = o.Release[i]
+		}
+		if c := compareNumeric(a, b); c != 0 {
+			return c
+		}
+	}
+	vp, op := v.preKey(), o.preKey()
+	for _, c := range []int{
+		compareInt(vp[0], op[0]),
+		compareInt(vp[1], op[1]),
+		compareInt(v.Post, o.Post),
+		compareInt(v.devKey(), o.devKey()),
+	} {
+		if c != 0 {
+			return c
+		}
+	}
+	return strings.Compare(v.Local, o.Local)
+}
+
+// preKey orders the pre-release segment: a dev release of the final version
+// ("1.0.dev1") before any pre-release, and the final version after them.
+func (v PEP440) preKey() [2]int {
+	switch {
+	case v.Pre >= 0:
+		return [2]int{v.Pre, v.PreN}
+	case v.Post < 0 && v.Dev >= 0:
+		return [2]int{-1, 0}
+	}
+	return [2]int{3, 0}
+}
+
+// devKey sorts a dev release before the release it leads to.
+func (v PEP440) devKey() int {
+	if v.Dev < 0 {
+		return math.MaxInt
+	}
+	return v.Dev
+}
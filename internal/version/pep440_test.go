@@ -0,0 +1,46 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+func TestPEP440_Compare(t *testing.T) {
+	comparePEP440 := func(a, b string) int {
+		va, err := version.ParsePEP440(a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		vb, err := version.ParsePEP440(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return va.Compare(vb)
+	}
+	checkCompare(t, comparePEP440, []compareCase{
+		{"2.0.0", "2.0.0", 0},
+		{"1.0", "1.0.0", 0},
+		{"2.0.0rc1", "2.0.0", -1},
+		{"2.0.0a1", "2.0.0b1", -1},
+		{"2.0.0b1", "2.0.0rc1", -1},
+		{"2.0.0rc1", "2.0.0rc2", -1},
+		{"2.0.0-RC1", "2.0.0rc1", 0},
+		{"1.0.dev1", "1.0a1", -1},
+		{"1.0a1.dev1", "1.0a1", -1},
+		{"1.0", "1.0.post1", -1},
+		{"1.0.post1.dev1", "1.0.post1", -1},
+		{"1.0-1", "1.0.post1", 0},
+		{"1!1.0", "2.0", 1},
+		{"1.10", "1.9", 1},
+		{"1.0+local", "1.0", 1},
+	})
+}
+
+func TestParsePEP440_Invalid(t *testing.T) {
+	for _, s := range []string{"", "1.0-foo", "latest"} {
+		if _, err := version.ParsePEP440(s); err == nil {
+			t.Errorf("ParsePEP440(%q) succeeded, want an error", s)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareRPM returns -1, 0 or 1 as rpm version a sorts before, equal to or
+// after b. Versions are "[epoch:]version[-release]"; a missing epoch is 0,
+// and releases are only compared when both versions have one.
+func CompareRPM(a, b string) int {
+	ea, va, ra := splitRPM(a)
+	eb, vb, rb := splitRPM(b)
+	if c := compareInt(ea, eb); c != 0 {
+		return c
+	}
+	if c := rpmvercmp(va, vb); c != 0 {
+		return c
+	}
+	if ra == "" || rb == "" {
+		return 0
+	}
+	return rpmvercmp(ra, rb)
+}
+
+func splitRPM(v string) (epoch int, version, release string) {
+	if e, rest, ok := strings.Cut(v, ":"); ok {
+		if n, err := strconv.Atoi(e); err == nil {
+			epoch, v = n, rest
+		}
+	}
+	if i := strings.LastIndex(v, "-"); i >= 0 {
+		return epoch, v[:i], v[i+1:]
+	}
+	return epoch, v, ""
+}
+
+// rpmvercmp is rpm's segment comparison: runs of digits compare numerically
+// and sort after runs of letters, other characters only separate segments,
+// "~" sorts before anything and "^" after the end of the string.
+func rpmvercmp(a, b string) int {
+	if a == b {
+		return 0
+	}
+	for a != "" || b != "" {
+		a, b = strings.TrimLeftFunc(a, rpmSeparator), strings.TrimLeftFunc(b, rpmSeparator)
+
+		if strings.HasPrefix(a, "~") || strings.HasPrefix(b, "~") {
+			if !strings.HasPrefix(a, "~") {
+				return 1
+			}
+			if !strings.HasPrefix(b, "~") {
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if strings.HasPrefix(a, "^") || strings.HasPrefix(b, "^") {
+			switch {
+			case a == "":
+				return -1
+			case b == "":
+				return 1
+			case a[0] != '^':
+				return 1
+			case b[0] != '^':
+				return -1
+			}
+			a, b = a[1:], b[1:]
+			continue
+		}
+		if a == "" || b == "" {
+			break
+		}
+
+		var sa, sb string
+		numeric := isDigit(a[0])
+		if numeric {
+			sa, a = digits(a)
+			sb, b = digits(b)
+		} else {
+			sa, a = letters(a)
+			sb, b = letters(b)
+		}
+		if sb == "" {
+			// Segments of different types: numeric is newer.
+			if numeric {
+				return 1
+			}
+			return -1
+		}
+		var c int
+		if numeric {
+			c = compareNumeric(sa, sb)
+		} else {
+			c = strings.Compare(sa, sb)
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	switch {
+	case a == "" && b == "":
+		return 0
+	case a == "":
+		return -1
+	}
+	return 1
+}
+
+func rpmSeparator(r rune) bool {
+	if r >= 0x80 {
+		return true
+	}
+	return !isDigit(byte(r)) && !isAlpha(byte(r)) && r != '~' && r != '^'
+}
+
+// letters splits the leading run of ASCII letters off s.
+func letters(s string) (run, rest string) {
+	i := 0
+	for i < len(s) && isAlpha(s[i]) {
+		i++
+	}
+	return s[:i], s[i:]
+}
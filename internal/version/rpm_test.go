@@ -0,0 +1,21 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+func TestCompareRPM(t *testing.T) {
+	checkCompare(t, version.CompareRPM, []compareCase{
+		{"3.1.1-4.fc39", "3.1.1-4.fc39", 0},
+		{"1:3.0-1", "3.1-1", 1},
+		{"0:3.0-1", "3.0-1", 0},
+		{"1.0-1.el9", "1.0-1.el9_2", -1},
+		{"1.0~rc1-1", "1.0-1", -1},
+		{"1.0^git1-1", "1.0-1", 1},
+		{"1.0a", "1.0.1", -1},
+		{"2.10", "2.9", 1},
+		{"1.0", "1.0-5", 0},
+	})
+}
@@ -0,0 +1,106 @@
+// Package version parses and compares version strings such as image tags
+// and package versions.
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Semver is a semantic version. Parsing is lenient in the ways image tags
+// need: a leading "v" is allowed and minor and patch may be omitted, so
+// "v1", "1.2" and "1.2.3-rc.1" all parse.
+type Semver struct {
+	Major, Minor, Patch uint64
+	Pre                 []string // dot-separated pre-release identifiers
+	Build               string
+}
+
+// ParseSemver parses s as a semantic version.
+func ParseSemver(s string) (Semver, error) {
+	var v Semver
+	rest := strings.TrimPrefix(s, "v")
+	rest, v.Build, _ = strings.Cut(rest, "+")
+	rest, pre, hasPre := strings.Cut(rest, "-")
+	if hasPre {
+		if pre == "" {
+			return Semver{}, fmt.Errorf("invalid semantic version %q: empty pre-release", s)
+		}
+		v.Pre = strings.Split(pre, ".")
+	}
+
+	parts := strings.Split(rest, ".")
+	if len(parts) > 3 {
+		return Semver{}, fmt.Errorf("invalid semantic version %q: too many components", s)
+	}
+	fields := []*uint64{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Semver{}, fmt.Errorf("invalid semantic version %q", s)
+		}
+		*fields[i] = n
+	}
+	return v, nil
+}
+
+// Compare returns -1, 0 or 1 as v sorts before, equal to or after o, using
+// semver precedence: a pre-release sorts before its release, and build
+// metadata is ignored.
+func (v Semver) Compare(o Semver) int {
+	for _, c := range [][2]uint64{{v.Major, o.Major}, {v.Minor, o.Minor}, {v.Patch, o.Patch}} {
+		if c[0] != c[1] {
+			if c[0] < c[1] {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case len(v.Pre) == 0 && len(o.Pre) == 0:
+		return 0
+	case len(v.Pre) == 0:
+		return 1
+	case len(o.Pre) == 0:
+		return -1
+	}
+	for i := 0; i < len(v.Pre) && i < len(o.Pre); i++ {
+		if c := comparePreIdent(v.Pre[i], o.Pre[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInt(len(v.Pre), len(o.Pre))
+}
+
+// comparePreIdent orders pre-release identifiers: numeric identifiers
+// numerically and before alphanumeric ones, which compare lexically.
+func comparePreIdent(a, b string) int {
+	na, errA := strconv.ParseUint(a, 10, 64)
+	nb, errB := strconv.ParseUint(b, 10, 64)
+	switch {
+	case errA == nil && errB == nil:
+		if na == nb {
+			return 0
+		}
+		if na < nb {
+			return -1
+		}
+		return 1
+	case errA == nil:
+		return -1
+	case errB == nil:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
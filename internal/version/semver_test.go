@@ -0,0 +1,52 @@
+package version_test
+
+import (
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+func TestParseSemver(t *testing.T) {
+	for _, s := range []string{"1", "v1.2", "1.2.3", "1.2.3-rc.1", "1.2.3+build.5"} {
+		if _, err := version.ParseSemver(s); err != nil {
+			t.Errorf("%s: unexpected error %v", s, err)
+		}
+	}
+	for _, s := range []string{"latest", "1.2.3.4", "1.x", "1.2-", ""} {
+		if _, err := version.ParseSemver(s); err == nil {
+			t.Errorf("%s: expected error", s)
+		}
+	}
+}
+
+func TestSemver_Compare(t *testing.T) {
+	cases := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"v2", "1.9.9", 1},
+		{"1.0.0-alpha", "1.0.0", -1},
+		{"1.0.0-alpha.1", "1.0.0-alpha.beta", -1},
+		{"1.0.0-beta.2", "1.0.0-beta.11", -1},
+		{"1.0.0-rc.1", "1.0.0-rc.1.1", -1},
+		{"1.0.0+a", "1.0.0+b", 0},
+	}
+	for _, tc := range cases {
+		a, err := version.ParseSemver(tc.a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := version.ParseSemver(tc.b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Compare(b); got != tc.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tc.a, tc.b, got, tc.want)
+		}
+		if got := b.Compare(a); got != -tc.want {
+			t.Errorf("Compare(%s, %s) = %d, want %d", tc.b, tc.a, got, -tc.want)
+		}
+	}
+}
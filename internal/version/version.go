@@ -0,0 +1,5 @@
+package version
+
+// Version is imgutil's own version, set at build time by the Makefile with
+// -ldflags "-X .../internal/version.Version=...".
+var Version = "dev"
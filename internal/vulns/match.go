@@ -0,0 +1,252 @@
+package vulns
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+	"github.com/thisisnotashwin/imgutil/internal/version"
+)
+
+// Finding is an advisory affecting an installed package.
+type Finding struct {
+	ID       string
+	Aliases  []string
+	Summary  string
+	Severity Severity
+	Package  inventory.Package
+	// Fixed is the first version that fixes the advisory, or "" if none is
+	// known.
+	Fixed string
+}
+
+// distroEcosystems maps os-release IDs to the OSV ecosystem of their
+// packages.
+var distroEcosystems = map[string]string{
+	"debian":              "Debian",
+	"ubuntu":              "Ubuntu",
+	"alpine":              "Alpine",
+	"wolfi":               "Wolfi",
+	"chainguard":          "Chainguard",
+	"rhel":                "Red Hat",
+	"almalinux":           "AlmaLinux",
+	"rocky":               "Rocky Linux",
+	"opensuse-leap":       "openSUSE",
+	"opensuse-tumbleweed": "openSUSE",
+	"sles":                "SUSE",
+}
+
+// languageEcosystems maps language package types to their OSV ecosystem.
+var languageEcosystems = map[string]string{
+	"go":     "Go",
+	"python": "PyPI",
+	"npm":    "npm",
+	"java":   "Maven",
+}
+
+// Match returns the advisories in db that affect the packages in inv, most
+// severe first.
+func (db *DB) Match(inv *inventory.Inventory) []Finding {
+	var findings []Finding
+	for _, p := range inv.Packages {
+		family := ecosystem(p, inv.OS)
+		if family == "" {
+			continue
+		}
+		compare := comparator(family)
+
+		// Distribution advisories name source packages.
+		names := []string{p.Name}
+		if p.Source != "" && p.Source != p.Name {
+			names = append(names, p.Source)
+		}
+		seen := map[string]bool{}
+		for _, n := range names {
+			for _, adv := range db.entries[key(family, n)] {
+				if seen[adv.ID] {
+					continue
+				}
+				for i := range adv.Affected {
+					a := &adv.Affected[i]
+					if !appliesTo(a, family, n, inv.OS) {
+						continue
+					}
+					fixed, ok := affects(a, p.Version, compare)
+					if !ok {
+						continue
+					}
+					seen[adv.ID] = true
+					findings = append(findings, Finding{
+						ID:       adv.ID,
+						Aliases:  adv.Aliases,
+						Summary:  adv.Summary,
+						Severity: severityOf(adv, a),
+						Package:  p,
+						Fixed:    fixed,
+					})
+					break
+				}
+			}
+		}
+	}
+
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return cmp.Or(
+			cmp.Compare(b.Severity, a.Severity),
+			cmp.Compare(a.Package.Name, b.Package.Name),
+			cmp.Compare(a.ID, b.ID),
+		)
+	})
+	return findings
+}
+
+// ecosystem returns the OSV ecosystem p belongs to, or "" if unknown.
+func ecosystem(p inventory.Package, os *inventory.OSRelease) string {
+	if eco, ok := languageEcosystems[p.Type]; ok {
+		return eco
+	}
+	if os != nil {
+		return distroEcosystems[os.ID]
+	}
+	// Without an os-release file, assume the distribution that defined the
+	// package format.
+	switch p.Type {
+	case "deb":
+		return "Debian"
+	case "apk":
+		return "Alpine"
+	}
+	return ""
+}
+
+// appliesTo reports whether a names the package n in family and, for
+// distribution ecosystems carrying a release such as "Debian:12" or
+// "Alpine:v3.19", whether that is the image's release.
+func appliesTo(a *affected, family, n string, os *inventory.OSRelease) bool {
+	fam, rest, hasRelease := strings.Cut(a.Package.Ecosystem, ":")
+	if fam != family || key(fam, a.Package.Name) != key(family, n) {
+		return false
+	}
+	if !hasRelease || os == nil || os.VersionID == "" {
+		return true
+	}
+	// The release is the first numeric field: "12", "22.04:LTS",
+	// "v3.19" or "enterprise_linux:9::appstream".
+	for _, f := range strings.Split(rest, ":") {
+		f = strings.TrimPrefix(f, "v")
+		if f != "" && f[0] >= '0' && f[0] <= '9' {
+			return os.VersionID == f || strings.HasPrefix(os.VersionID, f+".")
+		}
+	}
+	return true
+}
+
+// comparator returns the version ordering used by an ecosystem.
+func comparator(family string) func(a, b string) int {
+	switch family {
+	case "Debian", "Ubuntu":
+		return version.CompareDpkg
+	case "Alpine", "Wolfi", "Chainguard":
+		return version.CompareApk
+	case "Red Hat", "AlmaLinux", "Rocky Linux", "openSUSE", "SUSE":
+		return version.CompareRPM
+	case "PyPI":
+		return comparePEP440
+	case "Maven":
+		return version.CompareMaven
+	}
+	return compareSemver
+}
+
+// compareSemver orders semantic versions, falling back to rpm's segment
+// comparison, which orders most dotted versions sensibly, when either is not
+// one.
+func compareSemver(a, b string) int {
+	va, errA := version.ParseSemver(a)
+	vb, errB := version.ParseSemver(b)
+	if errA != nil || errB != nil {
+		return version.CompareRPM(a, b)
+	}
+	return va.Compare(vb)
+}
+
+// comparePEP440 orders Python versions, falling back to rpm's segment
+// comparison when either is not a PEP 440 version.
+func comparePEP440(a, b string) int {
+	va, errA := version.ParsePEP440(a)
+	vb, errB := version.ParsePEP440(b)
+	if errA != nil || errB != nil {
+		return version.CompareRPM(a, b)
+	}
+	return va.Compare(vb)
+}
+
+// affects reports whether v is within a's affected versions and returns the
+// version fixing it, if known.
+func affects(a *affected, v string, compare func(a, b string) int) (fixed string, ok bool) {
+	for _, r := range a.Ranges {
+		if r.Type == "GIT" {
+			continue
+		}
+		if fixed, ok := inRange(r.Events, v, compare); ok {
+			return fixed, true
+		}
+	}
+	if slices.Contains(a.Versions, v) {
+		return "", true
+	}
+	return "", false
+}
+
+// inRange evaluates a range's events for v. Events are sorted by version
+// and applied in order up to v: introduced starts an affected span, fixed and
+// limit end one, and last_affected ends one after it.
+func inRange(events []event, v string, compare func(a, b string) int) (fixed string, ok bool) {
+	at := func(e event) string {
+		return cmp.Or(e.Introduced, e.Fixed, e.LastAffected, e.Limit)
+	}
+	sorted := slices.Clone(events)
+	slices.SortStableFunc(sorted, func(a, b event) int {
+		va, vb := at(a), at(b)
+		switch {
+		case va == vb:
+			return 0
+		case a.Introduced == "0":
+			return -1
+		case b.Introduced == "0":
+			return 1
+		}
+		return compare(va, vb)
+	})
+
+	affected := false
+	for _, e := range sorted {
+		switch {
+		case e.Introduced != "":
+			if e.Introduced != "0" && compare(v, e.Introduced) < 0 {
+				return "", affected
+			}
+			affected = true
+		case e.Fixed != "":
+			if compare(v, e.Fixed) < 0 {
+				if affected {
+					return e.Fixed, true
+				}
+				return "", false
+			}
+			affected = false
+		case e.LastAffected != "":
+			if compare(v, e.LastAffected) <= 0 {
+				return "", affected
+			}
+			affected = false
+		case e.Limit != "" && e.Limit != "*":
+			if compare(v, e.Limit) < 0 {
+				return "", affected
+			}
+			affected = false
+		}
+	}
+	return "", affected
+}
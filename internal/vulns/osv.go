@@ -0,0 +1,182 @@
+// Package vulns matches an image's package inventory against a local
+// database of advisories in the OSV format (https://ossf.github.io/osv-schema/),
+// so images can be checked without network access.
+package vulns
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// advisory is one OSV record. Only the fields used for matching are decoded.
+type advisory struct {
+	ID               string        `json:"id"`
+	Summary          string        `json:"summary"`
+	Aliases          []string      `json:"aliases"`
+	Withdrawn        string        `json:"withdrawn"`
+	Severity         []osvSeverity `json:"severity"`
+	Affected         []affected    `json:"affected"`
+	DatabaseSpecific specific      `json:"database_specific"`
+}
+
+type osvSeverity struct {
+	Type  string `json:"type"`
+	Score string `json:"score"`
+}
+
+type affected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges            []osvRange `json:"ranges"`
+	Versions          []string   `json:"versions"`
+	EcosystemSpecific specific   `json:"ecosystem_specific"`
+	DatabaseSpecific  specific   `json:"database_specific"`
+}
+
+type osvRange struct {
+	Type   string  `json:"type"` // "ECOSYSTEM", "SEMVER" or "GIT"
+	Events []event `json:"events"`
+}
+
+type event struct {
+	Introduced   string `json:"introduced"`
+	Fixed        string `json:"fixed"`
+	LastAffected string `json:"last_affected"`
+	Limit        string `json:"limit"`
+}
+
+// specific holds the free-form database_specific and ecosystem_specific
+// objects, of which only a severity rating is read.
+type specific struct {
+	Severity any `json:"severity"`
+}
+
+// DB is a set of advisories indexed by ecosystem and package name.
+type DB struct {
+	// entries maps ecosystem family and package name to the advisories
+	// affecting that package, in load order.
+	entries map[string][]*advisory
+	size    int
+}
+
+// Len returns the number of advisories loaded.
+func (db *DB) Len() int { return db.size }
+
+// Load reads advisories from path: a directory of OSV JSON files (searched
+// recursively), a zip archive of them as in the OSV bulk exports, or a single
+// JSON file holding one advisory or an array of them.
+func Load(path string) (*DB, error) {
+	db := &DB{entries: map[string][]*advisory{}}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case info.IsDir():
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || filepath.Ext(p) != ".json" {
+				return err
+			}
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			if err := db.add(b); err != nil {
+				return fmt.Errorf("reading %s: %w", p, err)
+			}
+			return nil
+		})
+	case filepath.Ext(path) == ".zip":
+		err = db.addZip(path)
+	default:
+		var b []byte
+		if b, err = os.ReadFile(path); err == nil {
+			err = db.add(b)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading advisories from %s: %w", path, err)
+	}
+	return db, nil
+}
+
+func (db *DB) addZip(path string) error {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = zr.Close() }()
+	for _, f := range zr.File {
+		if filepath.Ext(f.Name) != ".json" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		b, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := db.add(b); err != nil {
+			return fmt.Errorf("reading %s: %w", f.Name, err)
+		}
+	}
+	return nil
+}
+
+// add decodes one advisory, or an array of them, and indexes it.
+func (db *DB) add(b []byte) error {
+	var advs []*advisory
+	if b = bytes.TrimSpace(b); len(b) > 0 && b[0] == '[' {
+		if err := json.Unmarshal(b, &advs); err != nil {
+			return err
+		}
+	} else {
+		adv := &advisory{}
+		if err := json.Unmarshal(b, adv); err != nil {
+			return err
+		}
+		advs = append(advs, adv)
+	}
+
+	for _, adv := range advs {
+		if adv.Withdrawn != "" {
+			continue
+		}
+		db.size++
+		seen := map[string]bool{}
+		for _, a := range adv.Affected {
+			family, _, _ := strings.Cut(a.Package.Ecosystem, ":")
+			k := key(family, a.Package.Name)
+			if !seen[k] {
+				seen[k] = true
+				db.entries[k] = append(db.entries[k], adv)
+			}
+		}
+	}
+	return nil
+}
+
+func key(family, name string) string {
+	if family == "PyPI" {
+		name = normalizePyPI(name)
+	}
+	return family + "\x00" + name
+}
+
+// normalizePyPI applies PEP 503 name normalisation.
+func normalizePyPI(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "-", ".", "-").Replace(name))
+}
@@ -0,0 +1,154 @@
+package vulns
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Severity rates an advisory. Advisories without a usable rating are Unknown,
+// which sorts below Low.
+type Severity int
+
+const (
+	Unknown Severity = iota
+	Low
+	Medium
+	High
+	Critical
+)
+
+// Severities lists the severity names, lowest first.
+var Severities = []string{"unknown", "low", "medium", "high", "critical"}
+
+func (s Severity) String() string {
+	return Severities[s]
+}
+
+// ParseSeverity parses a severity name. Synonyms used by advisory databases
+// ("negligible", "moderate", "important") are accepted.
+func ParseSeverity(s string) (Severity, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "unknown", "":
+		return Unknown, nil
+	case "low", "negligible":
+		return Low, nil
+	case "medium", "moderate":
+		return Medium, nil
+	case "high", "important":
+		return High, nil
+	case "critical":
+		return Critical, nil
+	}
+	return Unknown, fmt.Errorf("unknown severity %q: want one of %s", s, strings.Join(Severities, ", "))
+}
+
+// severityOf rates adv as it applies to a: by its CVSS v3 vector if it has
+// one, else by a rating from the advisory's database.
+func severityOf(adv *advisory, a *affected) Severity {
+	for _, s := range adv.Severity {
+		if s.Type != "CVSS_V3" {
+			continue
+		}
+		if score, ok := cvss3Score(s.Score); ok {
+			return scoreSeverity(score)
+		}
+	}
+	for _, s := range adv.Severity {
+		if s.Type == "Ubuntu" {
+			if sev, err := ParseSeverity(s.Score); err == nil {
+				return sev
+			}
+		}
+	}
+	for _, v := range []any{a.EcosystemSpecific.Severity, a.DatabaseSpecific.Severity, adv.DatabaseSpecific.Severity} {
+		if s, ok := v.(string); ok {
+			if sev, err := ParseSeverity(s); err == nil && sev != Unknown {
+				return sev
+			}
+		}
+	}
+	return Unknown
+}
+
+// scoreSeverity maps a CVSS score to its qualitative rating.
+func scoreSeverity(score float64) Severity {
+	switch {
+	case score >= 9:
+		return Critical
+	case score >= 7:
+		return High
+	case score >= 4:
+		return Medium
+	case score > 0:
+		return Low
+	}
+	return Unknown
+}
+
+// cvss3Weights holds the CVSS v3 base metric weights. Privileges Required
+// weighs more when the scope changes; see cvss3Score.
+var cvss3Weights = map[string]map[string]float64{
+	"AV": {"N": 0.85, "A": 0.62, "L": 0.55, "P": 0.2},
+	"AC": {"L": 0.77, "H": 0.44},
+	"PR": {"N": 0.85, "L": 0.62, "H": 0.27},
+	"UI": {"N": 0.85, "R": 0.62},
+	"C":  {"H": 0.56, "L": 0.22, "N": 0},
+	"I":  {"H": 0.56, "L": 0.22, "N": 0},
+	"A":  {"H": 0.56, "L": 0.22, "N": 0},
+}
+
+// cvss3Score computes the base score of a CVSS v3.0 or v3.1 vector such as
+// "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H".
+func cvss3Score(vector string) (float64, bool) {
+	parts := strings.Split(vector, "/")
+	if len(parts) == 0 || !strings.HasPrefix(parts[0], "CVSS:3") {
+		return 0, false
+	}
+	m := map[string]string{}
+	for _, p := range parts[1:] {
+		k, v, _ := strings.Cut(p, ":")
+		m[k] = v
+	}
+	if m["S"] != "U" && m["S"] != "C" {
+		return 0, false
+	}
+	changed := m["S"] == "C"
+	w := map[string]float64{}
+	for metric, weights := range cvss3Weights {
+		v, ok := weights[m[metric]]
+		if !ok {
+			return 0, false
+		}
+		w[metric] = v
+	}
+	if changed && m["PR"] == "L" {
+		w["PR"] = 0.68
+	} else if changed && m["PR"] == "H" {
+		w["PR"] = 0.5
+	}
+
+	iss := 1 - (1-w["C"])*(1-w["I"])*(1-w["A"])
+	impact := 6.42 * iss
+	if changed {
+		impact = 7.52*(iss-0.029) - 3.25*math.Pow(iss-0.02, 15)
+	}
+	if impact <= 0 {
+		return 0, true
+	}
+	exploitability := 8.22 * w["AV"] * w["AC"] * w["PR"] * w["UI"]
+	if changed {
+		return roundUp(math.Min(1.08*(impact+exploitability), 10)), true
+	}
+	return roundUp(math.Min(impact+exploitability, 10)), true
+}
+
+// roundUp is the specification's Roundup: the smallest number with one
+// decimal place that is not less than x, computed to avoid float error.
+func roundUp(x float64) float64 {
+	i := int64(math.Round(x * 100000))
+	if i%10000 == 0 {
+		return float64(i) / 100000
+	}
+	return float64(i/10000+1) / 10
+}
@@ -0,0 +1,181 @@
+package vulns_test
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/thisisnotashwin/imgutil/internal/inventory"
+	"github.com/thisisnotashwin/imgutil/internal/vulns"
+)
+
+const (
+	glibcAdvisory = `{
+  "id": "DSA-0001-1",
+  "summary": "glibc buffer overflow",
+  "aliases": ["CVE-2024-0001"],
+  "severity": [{"type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"}],
+  "affected": [{
+    "package": {"ecosystem": "Debian:12", "name": "glibc"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.36-9+deb12u4"}]}]
+  }]
+}`
+	oldDebianAdvisory = `{
+  "id": "DSA-0002-1",
+  "affected": [{
+    "package": {"ecosystem": "Debian:11", "name": "glibc"},
+    "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.40-1"}]}]
+  }]
+}`
+	pypiAdvisories = `[
+  {
+    "id": "GHSA-aaaa",
+    "database_specific": {"severity": "MODERATE"},
+    "affected": [{
+      "package": {"ecosystem": "PyPI", "name": "Typing_Extensions"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "4.0.0"}, {"last_affected": "4.9.0"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-bbbb",
+    "withdrawn": "2024-01-01T00:00:00Z",
+    "affected": [{"package": {"ecosystem": "PyPI", "name": "typing-extensions"}, "versions": ["4.9.0"]}]
+  }
+]`
+)
+
+func testInventory() *inventory.Inventory {
+	return &inventory.Inventory{
+		OS: &inventory.OSRelease{ID: "debian", VersionID: "12"},
+		Packages: []inventory.Package{
+			{Type: "deb", Name: "libc6", Version: "2.36-9+deb12u3", Source: "glibc", Arch: "amd64"},
+			{Type: "python", Name: "typing_extensions", Version: "4.9.0"},
+			{Type: "python", Name: "requests", Version: "2.31.0"},
+		},
+	}
+}
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestMatch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "debian/DSA-0001-1.json", glibcAdvisory)
+	writeFile(t, dir, "debian/DSA-0002-1.json", oldDebianAdvisory)
+	writeFile(t, dir, "pypi.json", pypiAdvisories)
+
+	db, err := vulns.Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.Len() != 3 {
+		t.Errorf("loaded %d advisories, want 3 (the withdrawn one skipped)", db.Len())
+	}
+
+	got := db.Match(testInventory())
+	if len(got) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(got), got)
+	}
+	if f := got[0]; f.ID != "DSA-0001-1" || f.Package.Name != "libc6" || f.Fixed != "2.36-9+deb12u4" || f.Severity != vulns.Critical {
+		t.Errorf("got %+v, want DSA-0001-1 on libc6, critical, fixed in 2.36-9+deb12u4", f)
+	}
+	if f := got[1]; f.ID != "GHSA-aaaa" || f.Severity != vulns.Medium || f.Fixed != "" {
+		t.Errorf("got %+v, want GHSA-aaaa, medium, with no fix", f)
+	}
+}
+
+func TestMatch_FixedVersion(t *testing.T) {
+	dir := t.TempDir()
+	p := writeFile(t, dir, "dsa.json", glibcAdvisory)
+	db, err := vulns.Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := testInventory()
+	inv.Packages[0].Version = "2.36-9+deb12u4"
+	if got := db.Match(inv); len(got) != 0 {
+		t.Errorf("got %+v, want no findings for the fixed version", got)
+	}
+}
+
+func TestLoad_Zip(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "all.zip")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, err := zw.Create("DSA-0001-1.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Write([]byte(glibcAdvisory)); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := vulns.Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := db.Match(testInventory()); len(got) != 1 {
+		t.Errorf("got %d findings, want 1", len(got))
+	}
+}
+
+func TestParseSeverity(t *testing.T) {
+	for s, want := range map[string]vulns.Severity{"high": vulns.High, "MODERATE": vulns.Medium, "negligible": vulns.Low} {
+		if got, err := vulns.ParseSeverity(s); err != nil || got != want {
+			t.Errorf("ParseSeverity(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	if _, err := vulns.ParseSeverity("severe"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
+
+func TestMatch_PreRelease(t *testing.T) {
+	dir := t.TempDir()
+	p := writeFile(t, dir, "advisories.json", `[
+  {
+    "id": "GHSA-cccc",
+    "affected": [{
+      "package": {"ecosystem": "PyPI", "name": "django"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "5.0.0"}]}]
+    }]
+  },
+  {
+    "id": "GHSA-dddd",
+    "affected": [{
+      "package": {"ecosystem": "Maven", "name": "org.example:lib"},
+      "ranges": [{"type": "ECOSYSTEM", "events": [{"introduced": "0"}, {"fixed": "2.0"}]}]
+    }]
+  }
+]`)
+	db, err := vulns.Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inv := &inventory.Inventory{Packages: []inventory.Package{
+		{Type: "python", Name: "Django", Version: "5.0.0rc1"},
+		{Type: "java", Name: "org.example:lib", Version: "2.0-beta1"},
+	}}
+	if got := db.Match(inv); len(got) != 2 {
+		t.Errorf("got %+v, want both pre-releases affected by the fix in their release", got)
+	}
+}